@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// cmdAdjust moves an entry's start, end, or both by relative amounts such
+// as +10m or -1h30m, for corrections that don't need full timestamps.
+func cmdAdjust(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("adjust", flag.ContinueOnError)
+	startBy := fs.Duration("start", 0, "move the start, e.g. +10m or -5m")
+	endBy := fs.Duration("end", 0, "move the end")
+	shiftBy := fs.Duration("shift", 0, "move the whole entry")
+	force := fs.Bool("force", false, "adjust even a locked entry")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	if *startBy == 0 && *endBy == 0 && *shiftBy == 0 {
+		fmt.Println("Nothing to adjust: use --start, --end or --shift.")
+		return
+	}
+	p := lookupProject(tracker, pos[0])
+	if p == nil {
+		fmt.Println(notFoundError(pos[0]))
+		return
+	}
+	if len(p.Logs) == 0 {
+		fmt.Printf("'%s' has no entries.\n", p.Name)
+		return
+	}
+	i := len(p.Logs) - 1
+	if len(pos) > 1 {
+		if i, err = entryIndex(p, pos[1]); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	e := p.Logs[i]
+	if !checkLocked(tracker, p.Name, e, *force) {
+		return
+	}
+	running := e.End.IsZero()
+	if running && (*endBy != 0 || *shiftBy != 0) {
+		fmt.Println("The session is running; only --start can be adjusted.")
+		return
+	}
+	e.Start = e.Start.Add(*startBy + *shiftBy)
+	if !running {
+		e.End = e.End.Add(*endBy + *shiftBy)
+	}
+	for j := range e.Pauses {
+		e.Pauses[j].Start = e.Pauses[j].Start.Add(*shiftBy)
+		if !e.Pauses[j].End.IsZero() {
+			e.Pauses[j].End = e.Pauses[j].End.Add(*shiftBy)
+		}
+	}
+	end := e.End
+	if running {
+		end = now
+	}
+	if !end.After(e.Start) {
+		fmt.Println("End must be after start.")
+		return
+	}
+	if !checkLocked(tracker, p.Name, e, *force) || !checkOverlap(p, e.Start, end, i, now) {
+		return
+	}
+	p.Logs[i] = e
+	recomputeTotal(p)
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	endLabel := "running"
+	if !running {
+		endLabel = e.End.Format("15:04:05")
+	}
+	fmt.Printf("Adjusted '%s' #%d: %s - %s (%.2fmin)\n", p.Name, i+1, e.Start.Format("2006-01-02 15:04:05"), endLabel, e.Duration(now).Minutes())
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The totals, day buckets and percentages here are shared by report, the
+// API, digest and budgets, which used to each carry their own copy.
+// verifyReport checks the invariants that keep them in agreement.
+
+// projectTotal is p's tracked time, counting a running session up to now.
+func projectTotal(p Project, now time.Time) time.Duration {
+	t := p.TotalTime
+	if e := p.running(); e != nil {
+		t += e.Duration(now)
+	}
+	return t
+}
+
+// percentShare is part as a percentage of total, or 0 when nothing was
+// tracked.
+func percentShare(part, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// dayTotals is p's worked time per UTC day.
+func dayTotals(p Project, now time.Time) map[string]time.Duration {
+	days := map[string]time.Duration{}
+	for _, e := range p.Logs {
+		addByDay(days, e, now)
+	}
+	return days
+}
+
+// verifyReport checks the report math on tracker, which must be fully
+// loaded: splitting each entry at midnight keeps its length, a project's
+// day buckets and its index add up to its total, and the percentages add
+// up to 100. It returns a line per violation.
+func verifyReport(tracker *TrackerData, idx *dailyIndex, now time.Time) []string {
+	var problems []string
+	var totals []time.Duration
+	var totalAll time.Duration
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			days := map[string]time.Duration{}
+			addByDay(days, e, now)
+			var split time.Duration
+			for _, d := range days {
+				split += d
+			}
+			if d := e.Duration(now); split != d {
+				problems = append(problems, fmt.Sprintf("%s: entry %s starting %s is %s but %s when split at midnight",
+					p.Name, e.ID, formatDate(e.Start)+" "+e.Start.Format(clockLayout(false)), d, split))
+			}
+		}
+		total := projectTotal(p, now)
+		var days time.Duration
+		for _, d := range dayTotals(p, now) {
+			days += d
+		}
+		if days != total {
+			problems = append(problems, fmt.Sprintf("%s: day buckets add up to %s but the total is %s", p.Name, days, total))
+		}
+		if t, n := rangeTotal(idx, p, time.Time{}, time.Time{}, now); t != total || n != len(p.Logs) {
+			problems = append(problems, fmt.Sprintf("%s: index has %s in %d sessions but the data has %s in %d",
+				p.Name, t, n, total, len(p.Logs)))
+		}
+		if p.Name != breakProject {
+			totals = append(totals, total)
+			totalAll += total
+		}
+	}
+	if totalAll > 0 {
+		var sum float64
+		for _, t := range totals {
+			sum += percentShare(t, totalAll)
+		}
+		if math.Abs(sum-100) > 1e-6 {
+			problems = append(problems, fmt.Sprintf("percentages add up to %g, not 100", sum))
+		}
+	}
+	return problems
+}
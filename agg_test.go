@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const aggSeeds = 200
+
+// randomTracker builds messier data than synthTracker: entries from a
+// minute to several days long, so they cross midnight, with pauses, and
+// running sessions (some paused) at the end of some projects.
+func randomTracker(r *rand.Rand, now time.Time) *TrackerData {
+	tracker := &TrackerData{}
+	for i := range 1 + r.Intn(5) {
+		p := Project{Name: fmt.Sprintf("p%d", i)}
+		if i == 0 && r.Intn(3) == 0 {
+			p.Name = breakProject
+		}
+		t := now.Add(-time.Duration(30+r.Intn(60)) * 24 * time.Hour)
+		for range r.Intn(30) {
+			t = t.Add(time.Duration(r.Intn(48*60)) * time.Minute)
+			length := time.Duration(1+r.Intn(4*24*60)) * time.Minute
+			if r.Intn(4) > 0 {
+				length = time.Duration(1+r.Intn(10*60)) * time.Minute
+			}
+			if !t.Add(length).Before(now) {
+				break
+			}
+			e := LogEntry{Start: t, End: t.Add(length)}
+			if r.Intn(3) == 0 {
+				ps := e.Start.Add(length / 4)
+				e.Pauses = []Pause{{Start: ps, End: ps.Add(length / time.Duration(2+r.Intn(4)))}}
+			}
+			p.Logs = append(p.Logs, e)
+			t = e.End
+		}
+		if r.Intn(3) == 0 {
+			e := LogEntry{Start: now.Add(-time.Duration(1+r.Intn(3*24*60)) * time.Minute)}
+			if len(p.Logs) == 0 || e.Start.After(p.Logs[len(p.Logs)-1].End) {
+				if r.Intn(2) == 0 {
+					e.Pauses = []Pause{{Start: e.Start.Add(now.Sub(e.Start) / 2)}}
+				}
+				p.Logs = append(p.Logs, e)
+			}
+		}
+		recomputeTotal(&p)
+		tracker.Projects = append(tracker.Projects, p)
+	}
+	return tracker
+}
+
+func randomNow(r *rand.Rand) time.Time {
+	return time.Date(2025, time.Month(1+r.Intn(12)), 1+r.Intn(28), r.Intn(24), r.Intn(60), r.Intn(60), 0, time.UTC)
+}
+
+func TestMidnightSplitKeepsTotal(t *testing.T) {
+	for seed := range aggSeeds {
+		r := rand.New(rand.NewSource(int64(seed)))
+		now := randomNow(r)
+		for _, p := range randomTracker(r, now).Projects {
+			for _, e := range p.Logs {
+				days := map[string]time.Duration{}
+				addByDay(days, e, now)
+				var sum time.Duration
+				for day, d := range days {
+					if d <= 0 || d > 24*time.Hour {
+						t.Errorf("seed %d: %s entry %s has %s on %s", seed, p.Name, e.Start, d, day)
+					}
+					sum += d
+				}
+				if want := e.Duration(now); sum != want {
+					t.Errorf("seed %d: %s entry %s split to %s, want %s", seed, p.Name, e.Start, sum, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDayBucketsSumToTotal(t *testing.T) {
+	for seed := range aggSeeds {
+		r := rand.New(rand.NewSource(int64(seed)))
+		now := randomNow(r)
+		for _, p := range randomTracker(r, now).Projects {
+			var sum time.Duration
+			for _, d := range dayTotals(p, now) {
+				sum += d
+			}
+			if want := projectTotal(p, now); sum != want {
+				t.Errorf("seed %d: %s day buckets add up to %s, want %s", seed, p.Name, sum, want)
+			}
+		}
+	}
+}
+
+func TestPercentagesSumTo100(t *testing.T) {
+	for seed := range aggSeeds {
+		r := rand.New(rand.NewSource(int64(seed)))
+		now := randomNow(r)
+		var totals []time.Duration
+		var all time.Duration
+		for _, p := range randomTracker(r, now).Projects {
+			d := projectTotal(p, now)
+			totals = append(totals, d)
+			all += d
+		}
+		var sum float64
+		for _, d := range totals {
+			sum += percentShare(d, all)
+		}
+		if all == 0 {
+			if sum != 0 {
+				t.Errorf("seed %d: percentages of nothing add up to %g", seed, sum)
+			}
+			continue
+		}
+		if math.Abs(sum-100) > 1e-6 {
+			t.Errorf("seed %d: percentages add up to %g", seed, sum)
+		}
+	}
+}
+
+// scanRange is what rangeTotal should report, from every entry.
+func scanRange(p Project, from, to, now time.Time) (total time.Duration, sessions int) {
+	for _, e := range p.Logs {
+		days := map[string]time.Duration{}
+		addByDay(days, e, now)
+		for day, d := range days {
+			t, _ := time.Parse(dateLayout, day)
+			if inRange(t, from, to) {
+				total += d
+			}
+		}
+		if inRange(e.Start, from, to) {
+			sessions++
+		}
+	}
+	return total, sessions
+}
+
+func TestIndexMatchesFullScan(t *testing.T) {
+	for seed := range aggSeeds {
+		r := rand.New(rand.NewSource(int64(seed)))
+		now := randomNow(r)
+		tracker := randomTracker(r, now)
+
+		// build it the way saves do: an earlier state first, then the rest
+		idx := &dailyIndex{Projects: map[string]*projectIndex{}}
+		earlier := &TrackerData{}
+		for _, p := range tracker.Projects {
+			p.Logs = p.Logs[:r.Intn(len(p.Logs)+1)]
+			earlier.Projects = append(earlier.Projects, p)
+		}
+		updateIndex(idx, earlier)
+		updateIndex(idx, tracker)
+
+		for range 5 {
+			var from, to time.Time
+			if r.Intn(4) > 0 {
+				from = now.Truncate(24*time.Hour).AddDate(0, 0, -r.Intn(100))
+			}
+			if r.Intn(4) > 0 {
+				to = now.Truncate(24*time.Hour).AddDate(0, 0, 1-r.Intn(100))
+			}
+			for _, p := range tracker.Projects {
+				got, gotN := rangeTotal(idx, p, from, to, now)
+				want, wantN := scanRange(p, from, to, now)
+				if got != want || gotN != wantN {
+					t.Errorf("seed %d: %s %s to %s: index has %s in %d sessions, scan %s in %d",
+						seed, p.Name, from, to, got, gotN, want, wantN)
+				}
+			}
+		}
+		if problems := verifyReport(tracker, idx, now); len(problems) > 0 {
+			t.Errorf("seed %d: %v", seed, problems)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// entryIndex converts a 1-based entry number as shown by stats into a
+// slice index for p.Logs.
+func entryIndex(p *Project, s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > len(p.Logs) {
+		return 0, fmt.Errorf("invalid entry '%s' for '%s' (1-%d)", s, p.Name, len(p.Logs))
+	}
+	return n - 1, nil
+}
+
+// cmdAnnotate sets an entry's note. With "." or "current" for the project
+// the entry number may be left out to annotate the running session.
+func cmdAnnotate(dataPath string, tracker *TrackerData, args []string) {
+	args, force := cutForce(args)
+	running := len(args) == 2 && isCurrentTarget(args[0])
+	if len(args) < 3 && !running {
+		fmt.Println("Project, entry number and note required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, args[0])
+	if p == nil {
+		fmt.Println(notFoundError(args[0]))
+		return
+	}
+	i, note := len(p.Logs)-1, args[1:]
+	if !running {
+		var err error
+		if i, err = entryIndex(p, args[1]); err != nil {
+			fmt.Println(err)
+			return
+		}
+		note = args[2:]
+	}
+	if !checkLocked(tracker, p.Name, p.Logs[i], force) {
+		return
+	}
+	p.Logs[i].Note = strings.Join(note, " ")
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Annotated '%s' #%d.\n", p.Name, i+1)
+}
+
+// cmdAmend annotates the most recently closed entry across all projects.
+func cmdAmend(dataPath string, tracker *TrackerData, args []string) {
+	args, force := cutForce(args)
+	if len(args) < 1 {
+		fmt.Println("Note required.\n", helpText)
+		return
+	}
+	var latest *LogEntry
+	var latestProject string
+	var latestIndex int
+	for pi := range tracker.Projects {
+		p := &tracker.Projects[pi]
+		for i := range p.Logs {
+			e := &p.Logs[i]
+			if e.End.IsZero() {
+				continue
+			}
+			if latest == nil || e.End.After(latest.End) {
+				latest, latestProject, latestIndex = e, p.Name, i
+			}
+		}
+	}
+	if latest == nil {
+		fmt.Println("No closed sessions.")
+		return
+	}
+	if !checkLocked(tracker, latestProject, *latest, force) {
+		return
+	}
+	latest.Note = strings.Join(args, " ")
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Annotated '%s' #%d.\n", latestProject, latestIndex+1)
+}
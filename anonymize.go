@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// anonymizer replaces names with salted hashes. The salt is random per
+// export, so names map consistently within one export but can't be
+// recovered by hashing a list of likely client names.
+type anonymizer struct {
+	salt []byte
+}
+
+func newAnonymizer() anonymizer {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return anonymizer{salt}
+}
+
+func (a anonymizer) name(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	h := hmac.New(sha256.New, a.salt)
+	h.Write([]byte(s))
+	return prefix + "-" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+func (a anonymizer) project(name string) string {
+	if name == breakProject {
+		return name
+	}
+	return a.name("project", name)
+}
+
+// anonymize returns a copy of tracker for sharing: project, user and tag
+// names are hashed; notes, descriptions, metadata, money and invoice and
+// push references are dropped. Timestamps, durations, pauses, budgets,
+// deadlines and states are kept as they are.
+func (a anonymizer) anonymize(tracker *TrackerData) *TrackerData {
+	out := &TrackerData{}
+	for _, p := range tracker.Projects {
+		ap := Project{
+			Name:      a.project(p.Name),
+			TotalTime: p.TotalTime,
+			Budget:    p.Budget,
+			Deadline:  p.Deadline,
+			State:     p.State,
+		}
+		for _, e := range p.Logs {
+			ae := LogEntry{
+				ID:         e.ID,
+				Modified:   e.Modified,
+				Start:      e.Start,
+				End:        e.End,
+				User:       a.name("user", e.User),
+				Billable:   e.Billable,
+				Until:      e.Until,
+				Source:     e.Source,
+				AutoClosed: e.AutoClosed,
+				Pauses:     e.Pauses,
+			}
+			for _, t := range e.Tags {
+				ae.Tags = append(ae.Tags, a.name("tag", t))
+			}
+			ap.Logs = append(ap.Logs, ae)
+		}
+		out.Projects = append(out.Projects, ap)
+	}
+	for _, lp := range tracker.Locks {
+		out.Locks = append(out.Locks, lockedPeriod{Project: a.project(lp.Project), From: lp.From, To: lp.To, Locked: lp.Locked})
+	}
+	return out
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The v1 API exposes the same operations as the CLI as typed JSON RPCs.
+// Request and response shapes are part of the versioned contract: add
+// fields, don't change existing ones.
+
+type sessionRequest struct {
+	Project string `json:"project"`
+	Note    string `json:"note,omitempty"`
+	// For timeboxes a started session, e.g. "45m".
+	For string `json:"for,omitempty"`
+	// Force starts a completed or archived project.
+	Force bool `json:"force,omitempty"`
+}
+
+type sessionResponse struct {
+	Project         string    `json:"project"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end,omitzero"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Note            string    `json:"note,omitempty"`
+	Paused          bool      `json:"paused,omitempty"`
+	Until           time.Time `json:"until,omitzero"`
+}
+
+type statusResponse struct {
+	Time   time.Time         `json:"time"`
+	Active []sessionResponse `json:"active"`
+}
+
+type reportRow struct {
+	Project      string  `json:"project"`
+	Sessions     int     `json:"sessions"`
+	TotalSeconds float64 `json:"totalSeconds"`
+	Percent      float64 `json:"percent"`
+}
+
+type reportResponse struct {
+	Projects     []reportRow `json:"projects"`
+	TotalSeconds float64     `json:"totalSeconds"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type apiServer struct {
+	store *store
+}
+
+// apiRoute describes one endpoint. The request and response models are
+// zero values of the JSON body types; serve --openapi documents the API
+// from this table, so every route must be registered through it.
+type apiRoute struct {
+	Method   string
+	Path     string
+	Summary  string
+	Query    []apiParam
+	Request  any
+	Response any
+	handler  func(*apiServer, http.ResponseWriter, *http.Request)
+}
+
+type apiParam struct {
+	Name, Type, Description string
+}
+
+var apiRoutes = []apiRoute{
+	{
+		Method: "POST", Path: "/v1/start", Summary: "Start a session",
+		Request: sessionRequest{}, Response: sessionResponse{},
+		handler: (*apiServer).handleStart,
+	},
+	{
+		Method: "POST", Path: "/v1/stop", Summary: "Stop the running session",
+		Request: sessionRequest{}, Response: sessionResponse{},
+		handler: (*apiServer).handleStop,
+	},
+	{
+		Method: "GET", Path: "/v1/status", Summary: "List running sessions",
+		Query: []apiParam{
+			{"stream", "boolean", "keep the connection open and write one JSON document per line"},
+			{"interval", "integer", "seconds between streamed documents (default 5)"},
+		},
+		Response: statusResponse{},
+		handler:  (*apiServer).handleStatus,
+	},
+	{
+		Method: "GET", Path: "/v1/report", Summary: "Total time per project",
+		Response: reportResponse{},
+		handler:  (*apiServer).handleReport,
+	},
+	{
+		Method: "POST", Path: "/v1/mail", Summary: "Add entries from a raw RFC 822 mail in the body",
+		Response: mailResponse{},
+		handler:  (*apiServer).handleMail,
+	},
+	{
+		Method: "POST", Path: "/intent", Summary: "Carry out a voice assistant intent and answer with a sentence to speak",
+		Request: intentRequest{}, Response: intentResponse{},
+		handler: (*apiServer).handleIntent,
+	},
+	{
+		Method: "POST", Path: "/heartbeat", Summary: "Record editor activity",
+		Request: heartbeatRequest{}, Response: sessionResponse{},
+		handler: (*apiServer).handleHeartbeat,
+	},
+}
+
+func (s *apiServer) register(mux *http.ServeMux) {
+	for _, rt := range apiRoutes {
+		h := rt.handler
+		mux.HandleFunc(rt.Method+" "+rt.Path, func(w http.ResponseWriter, r *http.Request) { h(s, w, r) })
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// errorStatus maps an error from a transaction to an HTTP status code.
+func errorStatus(err error) int {
+	var nf notFoundError
+	switch {
+	case errors.As(err, &nf):
+		return http.StatusNotFound
+	case errors.Is(err, errAlreadyActive), errors.Is(err, errNotActive), errors.As(err, new(closedProjectError)):
+		return http.StatusConflict
+	case errors.Is(err, errInvalidDuration), errors.As(err, new(ambiguousError)):
+		return http.StatusBadRequest
+	case errors.Is(err, errReadOnly):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, errorStatus(err), errorResponse{Error: err.Error()})
+}
+
+func newSessionResponse(name string, e LogEntry, now time.Time) sessionResponse {
+	return sessionResponse{
+		Project:         name,
+		Start:           e.Start,
+		End:             e.End,
+		DurationSeconds: e.Duration(now).Seconds(),
+		Note:            e.Note,
+		Paused:          e.paused(),
+		Until:           e.Until,
+	}
+}
+
+// sessionOp is the Start or Stop RPC, shared by the JSON and gRPC APIs.
+type sessionOp func(*TrackerData, sessionRequest, time.Time) (*Project, error)
+
+// runSession applies op in a transaction named desc and returns the
+// session it started or stopped.
+func (s *apiServer) runSession(desc string, req sessionRequest, op sessionOp) (sessionResponse, error) {
+	now := time.Now().UTC()
+	var resp sessionResponse
+	err := s.store.update(desc+" "+req.Project, func(tracker *TrackerData) error {
+		p, err := op(tracker, req, now)
+		if err != nil {
+			return err
+		}
+		resp = newSessionResponse(p.Name, p.Logs[len(p.Logs)-1], now)
+		return nil
+	})
+	return resp, err
+}
+
+func (s *apiServer) sessionRPC(w http.ResponseWriter, r *http.Request, op sessionOp) {
+	var req sessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Project == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "project required"})
+		return
+	}
+	resp, err := s.runSession(r.URL.Path, req, op)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func apiStart(tracker *TrackerData, req sessionRequest, now time.Time) (*Project, error) {
+	var d time.Duration
+	if req.For != "" {
+		var err error
+		if d, err = parseTimebox(req.For); err != nil {
+			return nil, err
+		}
+	}
+	p, err := startSession(tracker, req.Project, req.Note, req.Force, now)
+	if err == nil && d > 0 {
+		p.Logs[len(p.Logs)-1].Until = now.Add(d)
+	}
+	return p, err
+}
+
+func apiStop(tracker *TrackerData, req sessionRequest, now time.Time) (*Project, error) {
+	if p := lookupProject(tracker, req.Project); p != nil {
+		req.Project = p.Name
+	}
+	p, _, err := stopSession(tracker, req.Project, req.Note, now)
+	return p, err
+}
+
+func (s *apiServer) handleStart(w http.ResponseWriter, r *http.Request) {
+	s.sessionRPC(w, r, apiStart)
+}
+
+func (s *apiServer) handleStop(w http.ResponseWriter, r *http.Request) {
+	s.sessionRPC(w, r, apiStop)
+}
+
+func buildStatus(tracker *TrackerData, now time.Time) statusResponse {
+	resp := statusResponse{Time: now, Active: []sessionResponse{}}
+	for _, p := range tracker.Projects {
+		if e := p.running(); e != nil {
+			resp.Active = append(resp.Active, newSessionResponse(p.Name, *e, now))
+		}
+	}
+	return resp
+}
+
+// handleStatus returns the active sessions. With ?stream=1 it keeps the
+// connection open and writes one JSON document per line every interval
+// seconds (default 5) until the client disconnects.
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("stream") == "" {
+		tracker, err := s.store.view()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, buildStatus(tracker, time.Now().UTC()))
+		return
+	}
+	interval := 5 * time.Second
+	if v, err := strconv.Atoi(r.URL.Query().Get("interval")); err == nil && v > 0 {
+		interval = time.Duration(v) * time.Second
+	}
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tracker, err := s.store.view()
+		if err != nil {
+			enc.Encode(errorResponse{Error: err.Error()})
+			return
+		}
+		if err := enc.Encode(buildStatus(tracker, time.Now().UTC())); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *apiServer) handleReport(w http.ResponseWriter, r *http.Request) {
+	tracker, err := s.store.view()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, buildReport(tracker, time.Now().UTC()))
+}
+
+func buildReport(tracker *TrackerData, now time.Time) reportResponse {
+	resp := reportResponse{Projects: []reportRow{}}
+	var totalAll time.Duration
+	totals := make([]time.Duration, len(tracker.Projects))
+	for i, p := range tracker.Projects {
+		totals[i] = projectTotal(p, now)
+		if p.Name != breakProject {
+			totalAll += totals[i]
+		}
+	}
+	for i, p := range tracker.Projects {
+		if p.Name == breakProject {
+			continue
+		}
+		resp.Projects = append(resp.Projects, reportRow{Project: p.Name, Sessions: len(p.Logs), TotalSeconds: totals[i].Seconds(), Percent: percentShare(totals[i], totalAll)})
+	}
+	resp.TotalSeconds = totalAll.Seconds()
+	return resp
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func archiveDir(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), "archives")
+}
+
+// listArchives returns the per-year archive files, oldest first.
+func listArchives(dataPath string) []string {
+	entries, _ := os.ReadDir(archiveDir(dataPath))
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(archiveDir(dataPath), e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// archiveSnapshotDir is where a snapshot keeps the archive files as they
+// were, for commands such as compact that change them too.
+func archiveSnapshotDir(snap string) string {
+	return strings.TrimSuffix(snap, filepath.Ext(snap)) + ".archives"
+}
+
+// snapshotArchives copies the archive files next to snapshot snap, so
+// restoring it rolls them back with the data file.
+func snapshotArchives(dataPath, snap string) error {
+	dir := archiveSnapshotDir(snap)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, f := range listArchives(dataPath) {
+		if err := copyFile(f, filepath.Join(dir, filepath.Base(f))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreArchives makes the archive files those saved by snapshotArchives
+// in dir, removing any written since.
+func restoreArchives(dataPath, dir string) error {
+	keep := map[string]bool{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(archiveDir(dataPath), 0755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		keep[e.Name()] = true
+		if err := copyFile(filepath.Join(dir, e.Name()), filepath.Join(archiveDir(dataPath), e.Name())); err != nil {
+			return err
+		}
+	}
+	for _, f := range listArchives(dataPath) {
+		if !keep[filepath.Base(f)] {
+			if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeArchives folds archived entries back in front of each project's
+// live entries so reports can span the whole history.
+func mergeArchives(dataPath string, tracker *TrackerData) error {
+	for _, file := range listArchives(dataPath) {
+		archived, err := loadTracker(file)
+		if err != nil {
+			return err
+		}
+		for _, ap := range archived.Projects {
+			p := findProject(tracker, ap.Name)
+			if p == nil {
+				tracker.Projects = append(tracker.Projects, ap)
+				continue
+			}
+			count := p.sessionCount() + len(ap.Logs)
+			p.Logs = append(ap.Logs, p.Logs...)
+			if p.sessions > 0 {
+				p.sessions = count
+			}
+			p.TotalTime += ap.TotalTime
+		}
+	}
+	return nil
+}
+
+func cmdCompact(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("compact", flag.ContinueOnError)
+	beforeStr := fs.String("before", "", "archive entries starting before this date")
+	yes := fs.Bool("yes", false, "compact without asking")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *beforeStr == "" {
+		fmt.Println("--before DATE required.\n", helpText)
+		return
+	}
+	before, err := parseDate(*beforeStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	byYear := map[int]*TrackerData{}
+	moved := 0
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		var keep []LogEntry
+		for _, e := range p.Logs {
+			if e.End.IsZero() || !e.Start.Before(before) {
+				keep = append(keep, e)
+				continue
+			}
+			year := e.Start.Year()
+			if byYear[year] == nil {
+				byYear[year] = &TrackerData{}
+			}
+			ap := findProject(byYear[year], p.Name)
+			if ap == nil {
+				byYear[year].Projects = append(byYear[year].Projects, Project{Name: p.Name})
+				ap = &byYear[year].Projects[len(byYear[year].Projects)-1]
+			}
+			ap.Logs = append(ap.Logs, e)
+			moved++
+		}
+		p.Logs = keep
+		recomputeTotal(p)
+	}
+	if moved == 0 {
+		fmt.Println("Nothing to compact.")
+		return
+	}
+	years := make([]int, 0, len(byYear))
+	for y := range byYear {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	for _, y := range years {
+		n := 0
+		for _, p := range byYear[y].Projects {
+			n += len(p.Logs)
+		}
+		fmt.Printf("  %d: %d entries to %s/%d.json\n", y, n, filepath.Base(archiveDir(dataPath)), y)
+	}
+	if !confirm(fmt.Sprintf("Move %d entries from before %s out of the data file?", moved, formatDate(before)), *yes) {
+		return
+	}
+
+	snap, ok := takeSnapshot(dataPath, "compact")
+	if !ok {
+		return
+	}
+	if snap != "" {
+		if err := snapshotArchives(dataPath, snap); err != nil {
+			fmt.Println("Error taking a snapshot of the archives:", err)
+			return
+		}
+	}
+	if err := os.MkdirAll(archiveDir(dataPath), 0755); err != nil {
+		fmt.Println("Error creating archive directory:", err)
+		return
+	}
+	// write archives before the data file so a failure never loses entries
+	for _, y := range years {
+		file := filepath.Join(archiveDir(dataPath), strconv.Itoa(y)+".json")
+		archive, err := loadTracker(file)
+		if err != nil {
+			fmt.Println("Error reading archive:", err)
+			return
+		}
+		for _, np := range byYear[y].Projects {
+			ap := findProject(archive, np.Name)
+			if ap == nil {
+				archive.Projects = append(archive.Projects, Project{Name: np.Name})
+				ap = &archive.Projects[len(archive.Projects)-1]
+			}
+			ap.Logs = append(ap.Logs, np.Logs...)
+			sort.Slice(ap.Logs, func(a, b int) bool { return ap.Logs[a].Start.Before(ap.Logs[b].Start) })
+			recomputeTotal(ap)
+		}
+		if err := writeTracker(file, archive); err != nil {
+			fmt.Println("Error writing archive:", err)
+			return
+		}
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Archived %d entries into %d yearly file(s) in %s\n", moved, len(years), archiveDir(dataPath))
+	printRestoreHint(snap)
+}
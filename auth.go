@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// API tokens for serve are created with 'ptracker token create' and kept
+// in api_tokens.json as name -> sha256 of the token, so the file can't be
+// used to authenticate if it leaks. Tokens listed in config ("api_tokens")
+// are accepted as well. Once any token exists, every request to serve
+// must carry one as "Authorization: Bearer TOKEN".
+
+func apiTokensPath(appDir string) string { return filepath.Join(appDir, "api_tokens.json") }
+
+func loadAPITokens(appDir string) (map[string]string, error) {
+	tokens := map[string]string{}
+	data, err := os.ReadFile(apiTokensPath(appDir))
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, json.Unmarshal(data, &tokens)
+}
+
+func saveAPITokens(appDir string, tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(apiTokensPath(appDir), data, 0600)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenChecker reports whether a presented token is valid, or nil when
+// no tokens are configured.
+func tokenChecker(appDir string) (func(string) bool, error) {
+	stored, err := loadAPITokens(appDir)
+	if err != nil {
+		return nil, err
+	}
+	var hashes [][]byte
+	for _, h := range stored {
+		hashes = append(hashes, []byte(h))
+	}
+	for _, t := range config.APITokens {
+		hashes = append(hashes, []byte(hashToken(t)))
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	return func(token string) bool {
+		h := []byte(hashToken(token))
+		ok := false
+		for _, x := range hashes {
+			if subtle.ConstantTimeCompare(h, x) == 1 {
+				ok = true
+			}
+		}
+		return ok
+	}, nil
+}
+
+func requireToken(next http.Handler, valid func(string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !valid(token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ptracker"`)
+			writeJSON(w, http.StatusUnauthorized, errorResponse{"invalid or missing token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func cmdToken(appDir string, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: token [create|list|revoke] [name]")
+		return
+	}
+	tokens, err := loadAPITokens(appDir)
+	if err != nil {
+		fmt.Println("Error reading tokens:", err)
+		return
+	}
+	switch args[0] {
+	case "list":
+		if len(tokens) == 0 {
+			fmt.Println("No tokens.")
+			return
+		}
+		var names []string
+		for name := range tokens {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println("- ", name)
+		}
+	case "create", "revoke":
+		if len(args) < 2 {
+			fmt.Println("Token name required.")
+			return
+		}
+		name := args[1]
+		_, exists := tokens[name]
+		if args[0] == "revoke" {
+			if !exists {
+				fmt.Printf("'%s' not found.\n", name)
+				return
+			}
+			delete(tokens, name)
+			if err := saveAPITokens(appDir, tokens); err != nil {
+				fmt.Println("Error saving tokens:", err)
+				return
+			}
+			fmt.Printf("Revoked '%s'.\n", name)
+			return
+		}
+		if exists {
+			fmt.Printf("Token '%s' exists.\n", name)
+			return
+		}
+		b := make([]byte, 24)
+		rand.Read(b)
+		token := hex.EncodeToString(b)
+		tokens[name] = hashToken(token)
+		if err := saveAPITokens(appDir, tokens); err != nil {
+			fmt.Println("Error saving tokens:", err)
+			return
+		}
+		fmt.Printf("Token '%s': %s\n", name, token)
+		fmt.Println("It is shown only once; restart serve to pick it up.")
+	default:
+		fmt.Printf("Unknown token command '%s'.\n", args[0])
+	}
+}
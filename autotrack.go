@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const sourceAutotrack = "autotrack"
+
+// AutotrackConfig maps the focused window to projects. Rules are tried in
+// order; the first whose App and Title patterns (regexps, both optional)
+// match wins.
+type AutotrackConfig struct {
+	Enabled  bool            `json:"enabled"`
+	Interval string          `json:"interval,omitempty"`
+	Rules    []AutotrackRule `json:"rules,omitempty"`
+}
+
+type AutotrackRule struct {
+	App     string `json:"app,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Project string `json:"project"`
+}
+
+type compiledRule struct {
+	app, title *regexp.Regexp
+	project    string
+}
+
+func compileAutotrackRules(rules []AutotrackRule) ([]compiledRule, error) {
+	var out []compiledRule
+	for _, r := range rules {
+		c := compiledRule{project: r.Project}
+		var err error
+		if r.App != "" {
+			if c.app, err = regexp.Compile(r.App); err != nil {
+				return nil, fmt.Errorf("autotrack rule for %s: %w", r.Project, err)
+			}
+		}
+		if r.Title != "" {
+			if c.title, err = regexp.Compile(r.Title); err != nil {
+				return nil, fmt.Errorf("autotrack rule for %s: %w", r.Project, err)
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func matchWindow(rules []compiledRule, app, title string) string {
+	for _, r := range rules {
+		if r.app != nil && !r.app.MatchString(app) {
+			continue
+		}
+		if r.title != nil && !r.title.MatchString(title) {
+			continue
+		}
+		return r.project
+	}
+	return ""
+}
+
+const windowsFocusScript = `Add-Type @"
+using System;using System.Runtime.InteropServices;using System.Text;
+public class W{[DllImport("user32.dll")]public static extern IntPtr GetForegroundWindow();
+[DllImport("user32.dll")]public static extern int GetWindowText(IntPtr h,StringBuilder s,int n);
+[DllImport("user32.dll")]public static extern uint GetWindowThreadProcessId(IntPtr h,out uint p);}
+"@
+$h=[W]::GetForegroundWindow();$s=New-Object System.Text.StringBuilder 512;[void][W]::GetWindowText($h,$s,512)
+$p=0;[void][W]::GetWindowThreadProcessId($h,[ref]$p);(Get-Process -Id $p).ProcessName;$s.ToString()`
+
+const macFocusScript = `tell application "System Events"
+	set p to first application process whose frontmost is true
+	set t to ""
+	try
+		set t to name of front window of p
+	end try
+	return (name of p) & linefeed & t
+end tell`
+
+// focusedWindow returns the application and title of the focused window.
+func focusedWindow() (app, title string, err error) {
+	var out []byte
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = exec.Command("osascript", "-e", macFocusScript).Output()
+	case "windows":
+		out, err = exec.Command("powershell", "-NoProfile", "-Command", windowsFocusScript).Output()
+	default:
+		var cls []byte
+		if cls, err = exec.Command("xdotool", "getactivewindow", "getwindowclassname").Output(); err != nil {
+			return "", "", err
+		}
+		var name []byte
+		if name, err = exec.Command("xdotool", "getactivewindow", "getwindowname").Output(); err != nil {
+			return "", "", err
+		}
+		out = append(append(cls, '\n'), name...)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	app, title, _ = strings.Cut(strings.TrimRight(string(out), "\r\n"), "\n")
+	return strings.TrimSpace(app), strings.TrimSpace(title), nil
+}
+
+// runAutotrack samples the focused window and starts/stops sessions it
+// owns. Sessions started by hand are never touched.
+func (s *apiServer) runAutotrack(cfg AutotrackConfig) {
+	rules, err := compileAutotrackRules(cfg.Rules)
+	if err != nil {
+		log.Println("autotrack:", err)
+		return
+	}
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil || interval <= 0 {
+		interval = 10 * time.Second
+	}
+	log.Println("autotrack: sampling every", interval)
+	current := ""
+	for range time.Tick(interval) {
+		app, title, err := focusedWindow()
+		if err != nil {
+			log.Println("autotrack: reading focused window:", err)
+			continue
+		}
+		project := matchWindow(rules, app, title)
+		if project == current {
+			continue
+		}
+		now := time.Now().UTC()
+		err = s.store.update("autotrack "+project, func(tracker *TrackerData) error {
+			if current != "" {
+				if p := findProject(tracker, current); p != nil {
+					if e := p.running(); e != nil && e.Source == sourceAutotrack {
+						stopSession(tracker, current, "", now)
+					}
+				}
+			}
+			if project != "" {
+				p, err := startSession(tracker, project, "", false, now)
+				if err == nil {
+					p.Logs[len(p.Logs)-1].Source = sourceAutotrack
+				} else if err != errAlreadyActive {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("autotrack:", err)
+			continue
+		}
+		log.Printf("autotrack: %q -> %q (%s: %s)", current, project, app, title)
+		current = project
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBackupKeep     = 10
+	defaultBackupInterval = time.Hour
+)
+
+// corruptError reports a data file that failed to parse or verify.
+type corruptError struct {
+	file   string
+	reason string
+}
+
+func (e *corruptError) Error() string {
+	return fmt.Sprintf("%s is corrupted: %s", e.file, e.reason)
+}
+
+func checksumProjects(projects []Project) (string, int, error) {
+	data, err := json.Marshal(projects)
+	if err != nil {
+		return "", 0, err
+	}
+	entries := 0
+	for _, p := range projects {
+		entries += len(p.Logs)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), entries, nil
+}
+
+// verifyTracker checks the stored checksum and entry count. Files written
+// before checksums existed have neither and are accepted as-is.
+func verifyTracker(filename string, tracker *TrackerData) error {
+	if tracker.Checksum == "" {
+		return nil
+	}
+	sum, entries, err := checksumProjects(tracker.Projects)
+	if err != nil {
+		return err
+	}
+	if entries != tracker.Entries {
+		return &corruptError{filename, fmt.Sprintf("expected %d entries, found %d", tracker.Entries, entries)}
+	}
+	if sum != tracker.Checksum {
+		return &corruptError{filename, "checksum mismatch"}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file and renames it into place so
+// a crash never leaves a half-written file behind.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+func backupDir(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), "backups")
+}
+
+// listBackups returns backup files for dataPath, newest first.
+func listBackups(dataPath string) []string {
+	dir := backupDir(dataPath)
+	entries, _ := os.ReadDir(dir)
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "data-") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// rotateBackup copies the current data file into the backup directory if
+// the newest backup is older than the configured interval, then prunes old
+// backups. A "backup_keep" below zero disables backups.
+func rotateBackup(dataPath string, now time.Time) error {
+	keep := config.BackupKeep
+	if keep < 0 {
+		return nil
+	}
+	if keep == 0 {
+		keep = defaultBackupKeep
+	}
+	interval := defaultBackupInterval
+	if d, err := time.ParseDuration(config.BackupInterval); err == nil {
+		interval = d
+	}
+	if _, err := os.Stat(dataPath); err != nil {
+		return nil
+	}
+	backups := listBackups(dataPath)
+	if len(backups) > 0 {
+		if fi, err := os.Stat(backups[0]); err == nil && now.Sub(fi.ModTime()) < interval {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(backupDir(dataPath), 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(backupDir(dataPath), "data-"+now.Format("20060102-150405")+filepath.Ext(dataPath))
+	if err := copyFile(dataPath, name); err != nil {
+		return err
+	}
+	backups = listBackups(dataPath)
+	for _, old := range backups[min(keep, len(backups)):] {
+		os.Remove(old)
+	}
+	return nil
+}
+
+// restoreLatestBackup offers to replace a corrupted data file with the
+// newest backup that verifies. The corrupted file is kept alongside.
+func restoreLatestBackup(dataPath string, cause error) (*TrackerData, error) {
+	for _, b := range listBackups(dataPath) {
+		tracker, err := loadTracker(b)
+		if err != nil {
+			continue
+		}
+		fmt.Println(cause)
+		if !isTerminal(os.Stdin) {
+			// confirm's --yes doesn't reach here; say how to do it instead
+			fmt.Printf("Restore the latest good backup with 'ptracker restore %s'.\n", filepath.Base(b))
+			return nil, cause
+		}
+		if !confirm(fmt.Sprintf("Restore latest good backup %s?", filepath.Base(b)), false) {
+			return nil, cause
+		}
+		if err := os.Rename(dataPath, dataPath+".corrupt-"+time.Now().UTC().Format("20060102-150405")); err != nil {
+			return nil, err
+		}
+		if err := copyFile(b, dataPath); err != nil {
+			return nil, err
+		}
+		fmt.Println("Restored.")
+		return tracker, nil
+	}
+	return nil, cause
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	synthNotes = []string{"", "", "review", "planning", "bug fixing", "meeting", "writing", "research", "deploy"}
+	synthTags  = []string{"deep-work", "admin", "client", "call", "ops"}
+)
+
+// synthTracker builds deterministic synthetic data: entries entries spread
+// evenly over projects projects, each project's laid back to back with
+// gaps, the last ending before end. The same seed and end always give the
+// same data.
+func synthTracker(projects, entries int, seed int64, end time.Time) *TrackerData {
+	r := rand.New(rand.NewSource(seed))
+	id := func() string { return fmt.Sprintf("%016x", r.Uint64()) }
+	tracker := &TrackerData{}
+	for i := range projects {
+		p := Project{Name: fmt.Sprintf("project-%03d", i+1)}
+		if r.Intn(4) == 0 {
+			p.Rate = float64(50 + 10*r.Intn(10))
+		}
+		t := end.Add(-time.Duration(r.Intn(48*60)) * time.Minute)
+		n := entries / projects
+		if i < entries%projects {
+			n++
+		}
+		logs := make([]LogEntry, n)
+		for j := n - 1; j >= 0; j-- {
+			length := time.Duration(15+r.Intn(165)) * time.Minute
+			e := LogEntry{ID: id(), Start: t.Add(-length), End: t, User: "user",
+				Note: synthNotes[r.Intn(len(synthNotes))]}
+			e.Modified = e.End
+			if r.Intn(5) == 0 {
+				e.Tags = []string{synthTags[r.Intn(len(synthTags))]}
+			}
+			if r.Intn(10) == 0 {
+				ps := e.Start.Add(length / 3)
+				e.Pauses = []Pause{{Start: ps, End: ps.Add(length / 6)}}
+			}
+			logs[j] = e
+			t = e.Start.Add(-time.Duration(r.Intn(24*60)) * time.Minute)
+		}
+		p.Logs = logs
+		recomputeTotal(&p)
+		tracker.Projects = append(tracker.Projects, p)
+	}
+	return tracker
+}
+
+// benchBackends names each storage driver, with the file driver split by
+// encoding.
+func benchBackends() []string {
+	var names []string
+	for name := range storageDrivers {
+		if name == "file" {
+			names = append(names, "file:json", "file:binary")
+		} else {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cmdBench times load, save and report on synthetic data for each
+// storage backend in a temporary directory. It is left out of help as a
+// tool for working on the storage layer.
+func cmdBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	projects := fs.Int("projects", 20, "synthetic projects")
+	entries := fs.Int("entries", 1000, "entries per project")
+	runs := fs.Int("runs", 5, "runs per measurement")
+	seed := fs.Int64("seed", 1, "random seed for the data")
+	only := fs.String("backend", "", "comma-separated backends to run (default: all)")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile to this file")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	backends := benchBackends()
+	if *only != "" {
+		want := strings.Split(*only, ",")
+		for _, b := range want {
+			if !slices.Contains(backends, b) {
+				fmt.Printf("Unknown backend '%s'. Use %s.\n", b, strings.Join(backends, ", "))
+				return
+			}
+		}
+		backends = want
+	}
+	if *projects < 1 || *entries < 1 || *runs < 1 {
+		fmt.Println("--projects, --entries and --runs must be positive.")
+		return
+	}
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+		pprof.StartCPUProfile(f)
+		defer pprof.StopCPUProfile()
+	}
+	now := time.Now().UTC()
+	tracker := synthTracker(*projects, *projects**entries, *seed, now)
+	fmt.Printf("%d projects x %d entries, %d runs\n\n", *projects, *entries, *runs)
+	fmt.Printf("%-12s | %-14s | %10s | %10s | %10s\n", "Backend", "Operation", "Min", "Median", "Max")
+	fmt.Println("-------------|----------------|------------|------------|-----------")
+	for _, b := range backends {
+		if err := benchBackend(b, tracker, *runs, now); err != nil {
+			fmt.Printf("%-12s | %v\n", b, err)
+		}
+	}
+}
+
+// benchBackend runs the measurements for one backend and prints a row
+// per operation.
+func benchBackend(backend string, tracker *TrackerData, runs int, now time.Time) error {
+	dir, err := os.MkdirTemp("", "ptracker-bench-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	driver, encoding, _ := strings.Cut(backend, ":")
+	saved := config.Storage
+	config.Storage = driver
+	defer func() { config.Storage = saved }()
+	dataPath := filepath.Join(dir, jsonDataFile)
+	if encoding == "binary" {
+		dataPath = filepath.Join(dir, binaryDataFile)
+	}
+	ops := []struct {
+		name string
+		prep func()
+		run  func() error
+	}{
+		{"save", nil, func() error { return writeTracker(dataPath, tracker) }},
+		{"load", nil, func() error { _, err := loadTracker(dataPath); return err }},
+		{"load summary", func() { os.Remove(summaryCachePath(dataPath)) }, func() error {
+			_, err := loadSummary(dataPath)
+			return err
+		}},
+		{"report", func() { os.Remove(indexPath(dataPath)) }, func() error {
+			idx, err := indexFor(dataPath, tracker.Checksum)
+			if err != nil {
+				return err
+			}
+			from := now.AddDate(0, 0, -30)
+			for _, p := range tracker.Projects {
+				rangeTotal(idx, p, from, time.Time{}, now)
+			}
+			return nil
+		}},
+	}
+	for _, op := range ops {
+		var times []time.Duration
+		for range runs {
+			if op.prep != nil {
+				op.prep()
+			}
+			start := time.Now()
+			if err := op.run(); err != nil {
+				return fmt.Errorf("%s: %w", op.name, err)
+			}
+			times = append(times, time.Since(start))
+		}
+		slices.Sort(times)
+		fmt.Printf("%-12s | %-14s | %10s | %10s | %10s\n", backend, op.name,
+			times[0].Round(time.Microsecond), times[len(times)/2].Round(time.Microsecond), times[len(times)-1].Round(time.Microsecond))
+	}
+	if fi, err := os.Stat(dataPath); err == nil {
+		fmt.Printf("%-12s | %-14s | %10s |\n", backend, "file size", fmt.Sprintf("%.1f MB", float64(fi.Size())/1e6))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// benchEnd fixes where the synthetic data ends, so runs are comparable.
+var benchEnd = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// benchSetup writes the data 'ptracker bench' uses, 20 projects x 500
+// entries, with backend to a temporary directory and returns the data
+// path and the tracker as saved.
+func benchSetup(b *testing.B, backend string) (string, *TrackerData) {
+	b.Helper()
+	driver, encoding, _ := strings.Cut(backend, ":")
+	saved := config.Storage
+	config.Storage = driver
+	b.Cleanup(func() { config.Storage = saved })
+	dataPath := filepath.Join(b.TempDir(), jsonDataFile)
+	if encoding == "binary" {
+		dataPath = filepath.Join(filepath.Dir(dataPath), binaryDataFile)
+	}
+	tracker := synthTracker(20, 20*500, 1, benchEnd)
+	if err := writeTracker(dataPath, tracker); err != nil {
+		b.Fatal(err)
+	}
+	return dataPath, tracker
+}
+
+// forEachBackend runs fn as a sub-benchmark per storage backend.
+func forEachBackend(b *testing.B, fn func(b *testing.B, dataPath string, tracker *TrackerData)) {
+	for _, backend := range benchBackends() {
+		b.Run(backend, func(b *testing.B) {
+			dataPath, tracker := benchSetup(b, backend)
+			b.ResetTimer()
+			fn(b, dataPath, tracker)
+		})
+	}
+}
+
+func BenchmarkSave(b *testing.B) {
+	forEachBackend(b, func(b *testing.B, dataPath string, tracker *TrackerData) {
+		for b.Loop() {
+			if err := writeTracker(dataPath, tracker); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkLoad(b *testing.B) {
+	forEachBackend(b, func(b *testing.B, dataPath string, _ *TrackerData) {
+		for b.Loop() {
+			if _, err := loadTracker(dataPath); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkLoadSummary(b *testing.B) {
+	forEachBackend(b, func(b *testing.B, dataPath string, _ *TrackerData) {
+		for b.Loop() {
+			b.StopTimer()
+			os.Remove(summaryCachePath(dataPath))
+			b.StartTimer()
+			if _, err := loadSummary(dataPath); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkReport(b *testing.B) {
+	forEachBackend(b, func(b *testing.B, dataPath string, tracker *TrackerData) {
+		from := benchEnd.AddDate(0, 0, -30)
+		for b.Loop() {
+			b.StopTimer()
+			os.Remove(indexPath(dataPath))
+			b.StartTimer()
+			idx, err := indexFor(dataPath, tracker.Checksum)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, p := range tracker.Projects {
+				rangeTotal(idx, p, from, time.Time{}, benchEnd)
+			}
+		}
+	})
+}
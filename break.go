@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// breakProject is the built-in pseudo-project rest periods are recorded
+// under. It is created on first use and reported separately from work.
+const breakProject = "break"
+
+func ensureBreakProject(tracker *TrackerData) *Project {
+	if p := findProject(tracker, breakProject); p != nil {
+		return p
+	}
+	tracker.Projects = append(tracker.Projects, Project{Name: breakProject})
+	return &tracker.Projects[len(tracker.Projects)-1]
+}
+
+func cmdBreak(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	p := ensureBreakProject(tracker)
+	if len(args) == 0 {
+		cmdStart(dataPath, tracker, []string{breakProject}, now)
+		return
+	}
+	d, err := time.ParseDuration(args[0])
+	if err != nil || d <= 0 {
+		fmt.Printf("Invalid duration '%s'.\n", args[0])
+		return
+	}
+	if p.running() != nil {
+		fmt.Println("Break already active.")
+		return
+	}
+	p.Logs = append(p.Logs, LogEntry{Start: now.Add(-d), End: now, User: currentUser()})
+	p.TotalTime += d
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Recorded %.2fmin break.\n", d.Minutes())
+}
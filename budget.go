@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// budgetThreshold is the share of a budget at which warnings start
+// ("budget_warn" in config, default 0.8).
+func budgetThreshold() float64 {
+	if config.BudgetWarn > 0 {
+		return config.BudgetWarn
+	}
+	return 0.8
+}
+
+// budgetLabel describes consumed vs remaining budget, or "" if p has none.
+func budgetLabel(p Project, now time.Time) string {
+	if p.Budget <= 0 {
+		return ""
+	}
+	used := projectTotal(p, now)
+	pct := used.Seconds() / p.Budget.Seconds() * 100
+	if used > p.Budget {
+		return fmt.Sprintf("%s of %s (%.0f%%, over by %s)", formatHM(used), formatHM(p.Budget), pct, formatHM(used-p.Budget))
+	}
+	return fmt.Sprintf("%s of %s (%.0f%%, %s left)", formatHM(used), formatHM(p.Budget), pct, formatHM(p.Budget-used))
+}
+
+// budgetWarning returns a warning once p has used the threshold share of
+// its budget, or "".
+func budgetWarning(p Project, now time.Time) string {
+	if p.Budget <= 0 {
+		return ""
+	}
+	used := projectTotal(p, now)
+	switch {
+	case used > p.Budget:
+		return fmt.Sprintf("'%s' is over its %s budget by %s.", p.Name, formatHM(p.Budget), formatHM(used-p.Budget))
+	case used.Seconds() >= budgetThreshold()*p.Budget.Seconds():
+		return fmt.Sprintf("'%s' has used %.0f%% of its %s budget.", p.Name, used.Seconds()/p.Budget.Seconds()*100, formatHM(p.Budget))
+	}
+	return ""
+}
+
+// runBudgets raises a "budget" alert when a project crosses the warning
+// threshold and again when it goes over budget.
+func (s *apiServer) runBudgets() {
+	levels := map[string]int{}
+	for range time.Tick(time.Minute) {
+		tracker, err := s.store.view()
+		if err != nil {
+			log.Println("budget:", err)
+			continue
+		}
+		now := time.Now().UTC()
+		for _, p := range tracker.Projects {
+			if p.Budget <= 0 {
+				continue
+			}
+			used := projectTotal(p, now)
+			level := 0
+			if used > p.Budget {
+				level = 2
+			} else if used.Seconds() >= budgetThreshold()*p.Budget.Seconds() {
+				level = 1
+			}
+			prev, seen := levels[p.Name]
+			levels[p.Name] = level
+			if seen && level > prev {
+				msg := budgetWarning(p, now)
+				log.Println("budget:", msg)
+				alert("budget", "Budget", msg)
+			}
+		}
+	}
+}
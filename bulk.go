@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cmdBulk applies one edit to every entry matching the filters, after
+// showing what will change. All changes are saved together or not at all.
+func cmdBulk(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("bulk", flag.ContinueOnError)
+	project := fs.String("project", "", "only entries of this project")
+	fromStr := fs.String("from", "", "only entries starting on or after this date")
+	toStr := fs.String("to", "", "only entries starting on or before this date")
+	var hasTags, addTags, removeTags listFlag
+	fs.Var(&hasTags, "tag", "only entries with this tag (repeatable)")
+	fs.Var(&addTags, "add-tag", "tag to add (repeatable)")
+	fs.Var(&removeTags, "remove-tag", "tag to remove (repeatable)")
+	billable := fs.Bool("set-billable", false, "mark entries billable")
+	nonBillable := fs.Bool("set-non-billable", false, "mark entries non-billable")
+	note := fs.String("note", "", "replace the note")
+	yes := fs.Bool("yes", false, "apply without asking")
+	force := fs.Bool("force", false, "also change entries in locked periods")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *billable && *nonBillable {
+		fmt.Println("Use only one of --set-billable and --set-non-billable.")
+		return
+	}
+	if len(addTags) == 0 && len(removeTags) == 0 && !*billable && !*nonBillable && *note == "" {
+		fmt.Println("Nothing to change: use --add-tag, --remove-tag, --set-billable, --set-non-billable or --note.")
+		return
+	}
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *project != "" {
+		p := lookupProject(tracker, *project)
+		if p == nil {
+			fmt.Println(notFoundError(*project))
+			return
+		}
+		*project = p.Name
+	}
+
+	type match struct {
+		p *Project
+		i int
+	}
+	var matches []match
+	locked := 0
+	for pi := range tracker.Projects {
+		p := &tracker.Projects[pi]
+		if *project != "" && p.Name != *project {
+			continue
+		}
+	entries:
+		for i, e := range p.Logs {
+			if !inRange(e.Start, from, to) {
+				continue
+			}
+			for _, t := range hasTags {
+				if !e.hasTag(t) {
+					continue entries
+				}
+			}
+			if lockedAt(tracker, p.Name, e) != nil && !*force {
+				locked++
+				continue
+			}
+			matches = append(matches, match{p, i})
+		}
+	}
+	if locked > 0 {
+		fmt.Printf("Skipping %d entries in locked periods (use --force to include them).\n", locked)
+	}
+	if len(matches) == 0 {
+		fmt.Println("No matching entries.")
+		return
+	}
+
+	var changes []string
+	for _, t := range addTags {
+		changes = append(changes, "add #"+t)
+	}
+	for _, t := range removeTags {
+		changes = append(changes, "remove #"+t)
+	}
+	switch {
+	case *billable:
+		changes = append(changes, "billable")
+	case *nonBillable:
+		changes = append(changes, "non-billable")
+	}
+	if *note != "" {
+		changes = append(changes, fmt.Sprintf("note %q", *note))
+	}
+	fmt.Printf("%d entries will be changed (%s):\n", len(matches), strings.Join(changes, ", "))
+	const preview = 10
+	for _, m := range matches[:min(preview, len(matches))] {
+		e := m.p.Logs[m.i]
+		fmt.Printf("  %s #%d %s %.2fmin %s\n", m.p.Name, m.i+1, e.Start.Format("2006-01-02 15:04"), e.Duration(now).Minutes(), e.describe())
+	}
+	if len(matches) > preview {
+		fmt.Printf("  ... and %d more\n", len(matches)-preview)
+	}
+	if !confirm("Apply?", *yes) {
+		return
+	}
+	for _, m := range matches {
+		e := &m.p.Logs[m.i]
+		for _, t := range addTags {
+			e.addTag(t)
+		}
+		for _, t := range removeTags {
+			e.removeTag(t)
+		}
+		switch {
+		case *billable:
+			e.Billable = nil
+		case *nonBillable:
+			f := false
+			e.Billable = &f
+		}
+		if *note != "" {
+			e.Note = *note
+		}
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Updated %d entries.\n", len(matches))
+}
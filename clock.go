@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Times of day are shown on a 24-hour clock unless "clock" in config or
+// the --12h flag asks for 12-hour AM/PM times. 12-hour times keep the
+// leading zero so columns still line up.
+func twelveHour() bool {
+	return config.Clock == "12h"
+}
+
+// clockLayout is the time.Format layout for a time of day, with or
+// without seconds.
+func clockLayout(seconds bool) string {
+	switch {
+	case twelveHour() && seconds:
+		return "03:04:05 PM"
+	case twelveHour():
+		return "03:04 PM"
+	case seconds:
+		return "15:04:05"
+	}
+	return "15:04"
+}
+
+// timestampLayout is a date and time with seconds, as listed by stats.
+func timestampLayout() string {
+	return "2006-01-02 " + clockLayout(true)
+}
+
+// agoLabel describes how long before now t was, e.g. "12 min ago" or "3
+// days ago", for "relative_times" in config.
+func agoLabel(t, now time.Time) string {
+	d := now.Sub(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit + " ago"
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	days := int(d.Hours() / 24)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case days < 14:
+		return plural(days, "day")
+	case days < 60:
+		return plural(days/7, "week")
+	case days < 730:
+		return plural(days/30, "month")
+	}
+	return plural(days/365, "year")
+}
+
+// stampLabel is t as stats lists it: a timestamp, or how long ago with
+// "relative_times" in config.
+func stampLabel(t, now time.Time) string {
+	if config.RelativeTimes {
+		return agoLabel(t, now)
+	}
+	return t.Format(timestampLayout())
+}
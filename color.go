@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var ansiColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+}
+
+var ansiEnabled = sync.OnceValue(enableANSI)
+
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout) && ansiEnabled()
+}
+
+func colorize(color, s string) string {
+	code, ok := ansiColors[color]
+	if !ok || !useColor() {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+// projectLabel renders the project's icon and name padded to width, wrapped
+// in its color. Padding happens before coloring so escape codes don't
+// throw off table alignment.
+func projectLabel(p Project, width int) string {
+	label := p.Name
+	if p.Icon != "" {
+		label = p.Icon + " " + p.Name
+	}
+	return colorize(p.Color, fmt.Sprintf("%-*s", width, label))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parsePeriod resolves a named period, a single day or a FROM..TO range
+// of days into a half-open [from, to) interval.
+func parsePeriod(s string, now time.Time) (from, to time.Time, err error) {
+	today, _ := parseDate("today", now)
+	switch s {
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case "this-week":
+		from = weekStart(today)
+		return from, from.AddDate(0, 0, 7), nil
+	case "last-week":
+		to = weekStart(today)
+		return to.AddDate(0, 0, -7), to, nil
+	case "this-month":
+		from = bucketOf(today, "month", fiscalCalendar{})
+		return from, from.AddDate(0, 1, 0), nil
+	case "last-month":
+		to = bucketOf(today, "month", fiscalCalendar{})
+		return to.AddDate(0, -1, 0), to, nil
+	}
+	if a, b, ok := strings.Cut(s, ".."); ok {
+		return parseDateRange(a, b, now)
+	}
+	if from, err = parseDate(s, now); err != nil {
+		return from, to, fmt.Errorf("invalid period %q: use this-week, last-week, this-month, last-month, a date or FROM..TO", s)
+	}
+	return from, from.AddDate(0, 0, 1), nil
+}
+
+// reportCompare prints per-project time in period a next to period b,
+// with the change from b to a.
+func reportCompare(idx *dailyIndex, projects []Project, a, b string, now time.Time) {
+	aFrom, aTo, err := parsePeriod(a, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	bFrom, bTo, err := parsePeriod(b, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("===================================================================")
+	fmt.Printf("Comparison: %s vs %s\n", rangeLabel(aFrom, aTo), rangeLabel(bFrom, bTo))
+	fmt.Println("===================================================================")
+	aCol, bCol := a, b
+	if len(aCol) > 10 || len(bCol) > 10 {
+		aCol, bCol = "A", "B"
+	}
+	fmt.Printf("%-16s | %10s | %10s | %10s | %7s\n", "Project", aCol, bCol, "Delta", "Change")
+	fmt.Println("-----------------|------------|------------|------------|--------")
+	var aAll, bAll time.Duration
+	for _, p := range projects {
+		at, _ := rangeTotal(idx, p, aFrom, aTo, now)
+		bt, _ := rangeTotal(idx, p, bFrom, bTo, now)
+		if at == 0 && bt == 0 {
+			continue
+		}
+		fmt.Printf("%s | %10s | %10s | %10s | %7s\n", projectLabel(p, 16), formatHM(at), formatHM(bt), signedHM(at-bt), percentChange(at, bt))
+		aAll += at
+		bAll += bt
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("%-16s | %10s | %10s | %10s | %7s\n", "Total", formatHM(aAll), formatHM(bAll), signedHM(aAll-bAll), percentChange(aAll, bAll))
+}
+
+func signedHM(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatHM(-d)
+	}
+	return "+" + formatHM(d)
+}
+
+func percentChange(now, before time.Duration) string {
+	if before == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", (now.Seconds()-before.Seconds())/before.Seconds()*100)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Config holds user settings read from config.json next to the data file.
+// Every field is optional; the zero value means the default behavior.
+type Config struct {
+	// Overlap controls manual add/edit of overlapping entries: "reject"
+	// (default) or "warn".
+	Overlap string `json:"overlap,omitempty"`
+
+	// User is recorded on new entries instead of the OS username.
+	User string `json:"user,omitempty"`
+
+	// HeartbeatIdle is the longest gap between editor heartbeats that is
+	// still counted as one session, e.g. "5m" (the default).
+	HeartbeatIdle string `json:"heartbeat_idle,omitempty"`
+
+	// Autotrack lets serve start and stop sessions from the focused window.
+	Autotrack AutotrackConfig `json:"autotrack,omitzero"`
+
+	// OnLock is what serve does to running sessions when the screen locks
+	// or the machine sleeps: "pause" (resumed on unlock) or "stop".
+	OnLock string `json:"on_lock,omitempty"`
+
+	// BackupKeep is how many data file backups to retain (default 10,
+	// negative disables backups); BackupInterval is the minimum time
+	// between them (default "1h").
+	BackupKeep     int    `json:"backup_keep,omitempty"`
+	BackupInterval string `json:"backup_interval,omitempty"`
+
+	// APITokens are accepted by serve in addition to those made with
+	// 'token create'.
+	APITokens []string `json:"api_tokens,omitempty"`
+
+	// SyncToken authenticates 'sync' against a 'ptracker server' URL.
+	SyncToken string `json:"sync_token,omitempty"`
+
+	// WeekStart is the first day of the week for weekly reports and
+	// this-week/last-week dates: "monday" (default) or "sunday".
+	WeekStart string `json:"week_start,omitempty"`
+
+	// Fiscal defines the fiscal year and its periods for reports.
+	Fiscal FiscalConfig `json:"fiscal,omitzero"`
+
+	// CostRates are internal hourly costs per user, overriding the
+	// project's cost rate in 'report --profitability'.
+	CostRates map[string]float64 `json:"cost_rates,omitempty"`
+
+	// Rounding rounds each billed entry on invoices and in
+	// 'report --rounded'.
+	Rounding RoundingConfig `json:"rounding,omitzero"`
+
+	// BudgetWarn is the share of a project budget at which warnings
+	// start, e.g. 0.9 (default 0.8).
+	BudgetWarn float64 `json:"budget_warn,omitempty"`
+
+	// Currency labels amounts computed from project rates, e.g. "EUR"
+	// or "$".
+	Currency string `json:"currency,omitempty"`
+
+	// SMTP is the mail server used by 'digest --email'.
+	SMTP SMTPConfig `json:"smtp,omitzero"`
+
+	// NotionToken is the integration token for 'push notion'.
+	NotionToken string `json:"notion_token,omitempty"`
+
+	// Templates are presets started with 'start @name'.
+	Templates map[string]SessionTemplate `json:"templates,omitempty"`
+
+	// Recurring blocks are added as entries by 'apply-recurring' and serve.
+	Recurring []RecurringBlock `json:"recurring,omitempty"`
+
+	// Webhook receives every session start and stop as JSON; a project's
+	// "webhook" metadata overrides it.
+	Webhook string `json:"webhook,omitempty"`
+
+	// MQTT publishes session start/stop events and status to a broker.
+	MQTT MQTTConfig `json:"mqtt,omitzero"`
+
+	// OTLP sends each completed session as a span to a collector.
+	OTLP OTLPConfig `json:"otlp,omitzero"`
+
+	// Telegram runs a bot in serve for starting and stopping sessions
+	// from a chat; alerts can be sent to it too.
+	Telegram TelegramConfig `json:"telegram,omitzero"`
+
+	// Discord receives a daily summary from serve.
+	Discord DiscordConfig `json:"discord,omitzero"`
+
+	// MailFrom lists the only senders whose mails 'mail' and serve turn
+	// into entries; any sender is accepted when it is empty.
+	MailFrom []string `json:"mail_from,omitempty"`
+
+	// Alerts choose how serve announces each kind of event, e.g.
+	// {"timebox": "notify,bell"}; see alert for the methods.
+	Alerts map[string]string `json:"alerts,omitempty"`
+
+	// Locale sets how reports show dates and decimals, e.g. "de_DE";
+	// LC_ALL, LC_TIME or LANG are used when it is empty.
+	Locale string `json:"locale,omitempty"`
+
+	// Clock is "12h" for AM/PM times of day, or "24h" (the default).
+	Clock string `json:"clock,omitempty"`
+
+	// RelativeTimes shows when sessions started and projects were last
+	// active as e.g. "12 min ago" in status, stats and list.
+	RelativeTimes bool `json:"relative_times,omitempty"`
+
+	// Prompt is the format of 'ptracker prompt', e.g. "⏱ %project
+	// %elapsed"; see promptSegment for the placeholders.
+	Prompt string `json:"prompt,omitempty"`
+
+	// Dangling controls the repair of sessions left running.
+	Dangling DanglingConfig `json:"dangling,omitzero"`
+
+	// Storage names the storage driver; "file" (the default) keeps data
+	// in data.json or data.bin in the profile directory.
+	Storage string `json:"storage,omitempty"`
+
+	// WAL makes saves of a JSON data file go through a write-ahead log,
+	// so a save interrupted part way is replayed on the next load.
+	WAL bool `json:"wal,omitempty"`
+
+	// ReadOnly disables every command that would modify the data file.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+var config Config
+
+func loadConfig(filename string) (Config, error) {
+	var c Config
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Destructive commands show what they will remove, then ask with confirm.
+// --yes answers for them; --force only overrides locked periods, so the
+// preview is still shown and asked about. Without a terminal on stdin
+// there is no one to ask, so confirm refuses rather than taking an answer
+// from a pipe.
+
+// cutYes strips --yes (or -y) from args for commands that don't use a
+// FlagSet, like cutForce does for --force.
+func cutYes(args []string) ([]string, bool) {
+	var rest []string
+	yes := false
+	for _, a := range args {
+		if a == "--yes" || a == "-yes" || a == "-y" {
+			yes = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, yes
+}
+
+// confirm asks question and reports whether the answer was yes; yes
+// skips the question.
+func confirm(question string, yes bool) bool {
+	if yes {
+		return true
+	}
+	if !isTerminal(os.Stdin) {
+		fmt.Println(question, "Not confirmed: stdin is not a terminal; rerun with --yes.")
+		return false
+	}
+	fmt.Print(question + " [y/N]: ")
+	line, _ := readAnswer(bufio.NewReader(os.Stdin))
+	if !answeredYes(line) {
+		fmt.Println("Cancelled.")
+		return false
+	}
+	return true
+}
+
+// answeredYes reports whether a line read for a [y/N] question says yes.
+// The whole line is read, so a Windows console's CRLF is trimmed too.
+func answeredYes(line string) bool {
+	r := strings.TrimSpace(line)
+	return r == "y" || r == "Y"
+}
+
+// previewReplace lists the projects whose entries change when the data is
+// replaced wholesale by in, for import --replace and restore.
+func previewReplace(tracker, in *TrackerData) {
+	counts := map[string]int{}
+	for _, p := range in.Projects {
+		counts[p.Name] = len(p.Logs)
+	}
+	var lines []string
+	for _, p := range tracker.Projects {
+		if n, ok := counts[p.Name]; !ok {
+			lines = append(lines, fmt.Sprintf("  %s: %d entries, removed", p.Name, len(p.Logs)))
+		} else if n != len(p.Logs) {
+			lines = append(lines, fmt.Sprintf("  %s: %d entries, %d after", p.Name, len(p.Logs), n))
+		}
+		delete(counts, p.Name)
+	}
+	for _, p := range in.Projects {
+		if _, added := counts[p.Name]; added {
+			lines = append(lines, fmt.Sprintf("  %s: new, %d entries", p.Name, len(p.Logs)))
+		}
+	}
+	const preview = 10
+	for _, l := range lines[:min(preview, len(lines))] {
+		fmt.Println(l)
+	}
+	if len(lines) > preview {
+		fmt.Printf("  ... and %d more\n", len(lines)-preview)
+	}
+}
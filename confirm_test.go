@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestAnsweredYes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"Y\n", true},
+		{"y\r\n", true},
+		{"Y\r\n", true},
+		{"  y \r\n", true},
+		{"y", true},
+		{"\n", false},
+		{"\r\n", false},
+		{"", false},
+		{"n\r\n", false},
+		{"yes\n", false},
+		{"yy\n", false},
+	}
+	for _, tt := range tests {
+		line, _ := readAnswer(bufio.NewReader(strings.NewReader(tt.input)))
+		if got := answeredYes(line); got != tt.want {
+			t.Errorf("answeredYes(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCutYes(t *testing.T) {
+	tests := []struct {
+		args []string
+		rest []string
+		yes  bool
+	}{
+		{[]string{"acme"}, []string{"acme"}, false},
+		{[]string{"acme", "--yes"}, []string{"acme"}, true},
+		{[]string{"-y", "acme", "--force"}, []string{"acme", "--force"}, true},
+		{[]string{"-yes"}, nil, true},
+	}
+	for _, tt := range tests {
+		rest, yes := cutYes(tt.args)
+		if yes != tt.yes || strings.Join(rest, " ") != strings.Join(tt.rest, " ") {
+			t.Errorf("cutYes(%q) = %q, %v, want %q, %v", tt.args, rest, yes, tt.rest, tt.yes)
+		}
+	}
+}
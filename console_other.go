@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// enableANSI reports whether the terminal understands escape sequences,
+// which every terminal outside Windows does.
+func enableANSI() bool {
+	return true
+}
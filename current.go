@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// The commands in this file are building blocks for automation tools
+// (Shortcuts, Keyboard Maestro, AutoHotkey). Their output is fixed: one
+// line on stdout and nothing else, messages on stderr, and the exit code
+// 0 when tracking, 1 when idle and 2 on errors, like 'status --project'.
+
+// currentSession returns the most recently started running session, or
+// the named project's.
+func currentSession(tracker *TrackerData, name string) (*Project, *LogEntry) {
+	var cur *Project
+	var entry *LogEntry
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		if name != "" && p.Name != name {
+			continue
+		}
+		if e := p.running(); e != nil && (entry == nil || e.Start.After(entry.Start)) {
+			cur, entry = p, e
+		}
+	}
+	return cur, entry
+}
+
+// isCurrentTarget reports whether name is one of the pseudo-targets for
+// the running project, as in 'ptracker stop .'.
+func isCurrentTarget(name string) bool {
+	return name == "." || name == "current"
+}
+
+// runningProject returns the running project, or nil unless exactly one
+// is running.
+func runningProject(tracker *TrackerData) *Project {
+	var found *Project
+	for i := range tracker.Projects {
+		if tracker.Projects[i].running() != nil {
+			if found != nil {
+				return nil
+			}
+			found = &tracker.Projects[i]
+		}
+	}
+	return found
+}
+
+// lookupProject is findProject for project names given on the command
+// line or to the API, where "." and "current" also stand for the running
+// project when exactly one is running, unless a project has that name.
+// Names read from files and config are plain names; use findProject.
+func lookupProject(tracker *TrackerData, name string) *Project {
+	if p := findProject(tracker, name); p != nil || !isCurrentTarget(name) {
+		return p
+	}
+	return runningProject(tracker)
+}
+
+// cmdCurrent prints the name of the running project.
+func cmdCurrent(tracker *TrackerData, args []string, now time.Time) int {
+	fs := flag.NewFlagSet("current", flag.ContinueOnError)
+	fs.Bool("plain", true, "print only the project name (the default)")
+	asJSON := fs.Bool("json", false, `print {"project", "start", "seconds", "paused"} instead`)
+	if _, err := parseFlags(fs, args); err != nil {
+		return 2
+	}
+	p, e := currentSession(tracker, "")
+	if p == nil {
+		if *asJSON {
+			fmt.Println("null")
+		}
+		return 1
+	}
+	if !*asJSON {
+		fmt.Println(p.Name)
+		return 0
+	}
+	data, err := json.Marshal(struct {
+		Project string    `json:"project"`
+		Start   time.Time `json:"start"`
+		Seconds int64     `json:"seconds"`
+		Paused  bool      `json:"paused"`
+	}{p.Name, e.Start, int64(e.Duration(now) / time.Second), e.paused()})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+// cmdElapsed prints how long the running session (or the named project's)
+// has been going, excluding pauses.
+func cmdElapsed(tracker *TrackerData, args []string, now time.Time) int {
+	fs := flag.NewFlagSet("elapsed", flag.ContinueOnError)
+	seconds := fs.Bool("seconds", false, "print whole seconds")
+	minutes := fs.Bool("minutes", false, "print whole minutes")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return 2
+	}
+	name := ""
+	if len(pos) > 0 {
+		p := lookupProject(tracker, pos[0])
+		if p == nil {
+			fmt.Fprintln(os.Stderr, notFoundError(pos[0]))
+			return 2
+		}
+		name = p.Name
+	}
+	_, e := currentSession(tracker, name)
+	if e == nil {
+		return 1
+	}
+	d := e.Duration(now).Truncate(time.Second)
+	switch {
+	case *seconds:
+		fmt.Println(int64(d / time.Second))
+	case *minutes:
+		fmt.Println(int64(d / time.Minute))
+	default:
+		fmt.Printf("%d:%02d:%02d\n", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return 0
+}
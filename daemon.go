@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+const systemdUnitName = "ptracker.service"
+const launchdLabel = "com.ptracker.daemon"
+
+var systemdUnit = template.Must(template.New("unit").Parse(`[Unit]
+Description=ptracker time tracking daemon
+
+[Service]
+ExecStart={{.ExecStart}}
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`))
+
+var launchdPlist = template.Must(template.New("plist").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{.Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args}}
+		<string>{{html .}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{html .LogPath}}</string>
+</dict>
+</plist>
+`))
+
+func servicePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if isTermux() {
+		return "", fmt.Errorf("daemon install is not supported on Termux; run 'ptracker serve' from Termux:Boot or termux-services")
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(home, ".config", "systemd", "user", systemdUnitName), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+	}
+	return "", fmt.Errorf("daemon install is not supported on %s", runtime.GOOS)
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func cmdDaemon(logPath, profile string, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Subcommand required: install, uninstall.\n", helpText)
+		return
+	}
+	path, err := servicePath()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	switch args[0] {
+	case "install":
+		fs := flag.NewFlagSet("daemon install", flag.ContinueOnError)
+		listen := fs.String("listen", "127.0.0.1:8787", "address for serve to listen on")
+		if _, err := parseFlags(fs, args[1:]); err != nil {
+			return
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Println("Error locating executable:", err)
+			return
+		}
+		serveArgs := []string{exe, "serve", "--listen", *listen}
+		if profile != defaultProfile {
+			serveArgs = append(serveArgs, "--profile", profile)
+		}
+		quoted := make([]string, len(serveArgs))
+		for i, a := range serveArgs {
+			quoted[i] = a
+			if strings.ContainsAny(a, " \t\"") {
+				quoted[i] = `"` + strings.ReplaceAll(a, `"`, `\"`) + `"`
+			}
+		}
+		var b strings.Builder
+		data := struct {
+			Label, LogPath, ExecStart string
+			Args                      []string
+		}{launchdLabel, logPath, strings.Join(quoted, " "), serveArgs}
+		if runtime.GOOS == "darwin" {
+			err = launchdPlist.Execute(&b, data)
+		} else {
+			err = systemdUnit.Execute(&b, data)
+		}
+		if err != nil {
+			fmt.Println("Error rendering service file:", err)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Println("Error creating directory:", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+			fmt.Println("Error writing service file:", err)
+			return
+		}
+		fmt.Println("Wrote", path)
+		if runtime.GOOS == "darwin" {
+			err = run("launchctl", "load", "-w", path)
+		} else if err = run("systemctl", "--user", "daemon-reload"); err == nil {
+			err = run("systemctl", "--user", "enable", "--now", systemdUnitName)
+		}
+		if err != nil {
+			fmt.Println("Error enabling service:", err)
+			return
+		}
+		fmt.Println("Daemon installed and started.")
+
+	case "uninstall":
+		if runtime.GOOS == "darwin" {
+			err = run("launchctl", "unload", "-w", path)
+		} else {
+			err = run("systemctl", "--user", "disable", "--now", systemdUnitName)
+		}
+		if err != nil {
+			fmt.Println("Error disabling service:", err)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fmt.Println("Error removing service file:", err)
+			return
+		}
+		fmt.Println("Daemon uninstalled.")
+
+	default:
+		fmt.Println("Unknown daemon command. Use install or uninstall.")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// DanglingConfig controls what happens to sessions left running: one
+// open for longer than After (default "24h") is closed Length (default
+// "8h") after it started, or when it was paused, per Policy: "ask" (the
+// default; left open when stdin isn't a terminal), "close" or "ignore".
+type DanglingConfig struct {
+	After  string `json:"after,omitempty"`
+	Length string `json:"length,omitempty"`
+	Policy string `json:"policy,omitempty"`
+}
+
+const (
+	defaultDanglingAfter  = 24 * time.Hour
+	defaultDanglingLength = 8 * time.Hour
+)
+
+// danglingClose is when a dangling entry is closed: when it was paused,
+// or length after it started.
+func danglingClose(e LogEntry, length time.Duration) time.Time {
+	if e.paused() {
+		return e.Pauses[len(e.Pauses)-1].Start
+	}
+	return e.Start.Add(length)
+}
+
+// repairDangling closes sessions that have been open longer than the
+// configured threshold, asking first unless the policy says otherwise,
+// and saves if any were closed.
+func repairDangling(dataPath string, tracker *TrackerData, c DanglingConfig, now time.Time) {
+	if c.Policy == "ignore" {
+		return
+	}
+	after, length := defaultDanglingAfter, defaultDanglingLength
+	if c.After != "" {
+		d, err := parseSpan(c.After)
+		if err != nil {
+			log.Println("dangling:", err)
+			return
+		}
+		after = d
+	}
+	if c.Length != "" {
+		d, err := parseSpan(c.Length)
+		if err != nil {
+			log.Println("dangling:", err)
+			return
+		}
+		length = d
+	}
+	repaired := false
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		e := p.running()
+		if e == nil || now.Sub(e.Start) < after {
+			continue
+		}
+		at := danglingClose(*e, length)
+		if at.After(now) {
+			at = now
+		}
+		if c.Policy != "close" {
+			// this runs before every mutating command, some of which read
+			// their input from stdin, so only ask someone at a terminal
+			if !isTerminal(os.Stdin) {
+				log.Printf("dangling: not asking about '%s': stdin is not a terminal", p.Name)
+				continue
+			}
+			q := fmt.Sprintf("'%s' has been running since %s (%s). Close it at %s?", p.Name, e.Start.Format("2006-01-02 15:04"), formatDays(now.Sub(e.Start)), at.Format("2006-01-02 15:04"))
+			if !confirm(q, false) {
+				continue
+			}
+		}
+		if _, _, err := stopSession(tracker, p.Name, "", at); err != nil {
+			continue
+		}
+		e.AutoClosed = true
+		log.Printf("dangling: closed '%s' started %s at %s", p.Name, e.Start.Format(time.RFC3339), at.Format(time.RFC3339))
+		fmt.Printf("Closed '%s' at %s.\n", p.Name, at.Format("2006-01-02 15:04"))
+		repaired = true
+	}
+	if repaired {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+		}
+	}
+}
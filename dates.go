@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDate accepts YYYY-MM-DD, "today", "yesterday", "this-week" or
+// "last-week" (the first day of the week, per "week_start") and returns
+// the start of that day in UTC.
+func parseDate(s string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch s {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "this-week":
+		return weekStart(today), nil
+	case "last-week":
+		return weekStart(today).AddDate(0, 0, -7), nil
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD, today, yesterday, this-week or last-week", s)
+	}
+	return t, nil
+}
+
+// parseDateRange resolves optional --from/--to values into a half-open
+// [from, to) interval; to is inclusive of its whole day.
+func parseDateRange(fromStr, toStr string, now time.Time) (from, to time.Time, err error) {
+	if fromStr != "" {
+		if from, err = parseDate(fromStr, now); err != nil {
+			return
+		}
+	}
+	if toStr != "" {
+		if to, err = parseDate(toStr, now); err != nil {
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return
+}
+
+// parseSpan parses a length of time as a Go duration or a number of days
+// or weeks, e.g. "30d" or "4w".
+func parseSpan(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(strings.TrimRight(s, "dw")); err == nil && n > 0 && len(s) > len(strconv.Itoa(n)) {
+		switch s[len(s)-1] {
+		case 'd':
+			return time.Duration(n) * 24 * time.Hour, nil
+		case 'w':
+			return time.Duration(n) * 7 * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid length %q, expected e.g. 30d, 4w or 12h", s)
+	}
+	return d, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
+
+// parseDateTime accepts RFC 3339, "YYYY-MM-DD HH:MM[:SS]" or a bare
+// "HH:MM[:SS]" meaning today, all in UTC.
+func parseDateTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected \"YYYY-MM-DD HH:MM\" or HH:MM", s)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// deadlineLabel counts the days to p's deadline, in red once it is due
+// or overdue, or returns "" if p has none.
+func deadlineLabel(p Project, now time.Time) string {
+	if p.Deadline.IsZero() {
+		return ""
+	}
+	today, _ := parseDate("today", now)
+	days := int(p.Deadline.Sub(today).Hours() / 24)
+	switch {
+	case days < 0:
+		return colorize("red", fmt.Sprintf("overdue by %d days (%s)", -days, formatDate(p.Deadline)))
+	case days == 0:
+		return colorize("red", "due today")
+	case days == 1:
+		return "due tomorrow"
+	}
+	return fmt.Sprintf("due in %d days (%s)", days, formatDate(p.Deadline))
+}
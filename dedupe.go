@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type duplicate struct {
+	keep, drop int
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// findDuplicates pairs closed entries of p whose start and end both lie
+// within tolerance of each other. Of each pair the entry with a note, or
+// else the earlier one, is kept.
+func findDuplicates(p *Project, tolerance time.Duration) []duplicate {
+	order := make([]int, 0, len(p.Logs))
+	for i, e := range p.Logs {
+		if !e.End.IsZero() {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return p.Logs[order[a]].Start.Before(p.Logs[order[b]].Start) })
+	var dups []duplicate
+	dropped := map[int]bool{}
+	for a, i := range order {
+		if dropped[i] {
+			continue
+		}
+		for _, j := range order[a+1:] {
+			ei, ej := p.Logs[i], p.Logs[j]
+			if ej.Start.Sub(ei.Start) > tolerance {
+				break
+			}
+			if dropped[j] || absDuration(ej.End.Sub(ei.End)) > tolerance {
+				continue
+			}
+			d := duplicate{keep: i, drop: j}
+			if ei.Note == "" && ej.Note != "" {
+				d = duplicate{keep: j, drop: i}
+			}
+			dups = append(dups, d)
+			dropped[d.drop] = true
+			if d.drop == i {
+				break
+			}
+		}
+	}
+	return dups
+}
+
+func cmdDedupe(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("dedupe", flag.ContinueOnError)
+	tolerance := fs.Duration("tolerance", time.Minute, "treat starts and ends this close as equal")
+	project := fs.String("project", "", "only check this project")
+	yes := fs.Bool("yes", false, "remove every duplicate without asking")
+	force := fs.Bool("force", false, "also remove duplicates in locked periods")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *project != "" {
+		p := lookupProject(tracker, *project)
+		if p == nil {
+			fmt.Println(notFoundError(*project))
+			return
+		}
+		*project = p.Name
+	}
+	in := bufio.NewReader(os.Stdin)
+	all := *yes
+	ask := isTerminal(os.Stdin)
+	found, removed := 0, 0
+	show := func(label string, n int, e LogEntry) {
+		fmt.Printf("  %s #%d %s - %s %s\n", label, n+1, e.Start.Format("2006-01-02 15:04:05"), e.End.Format("15:04:05"), e.Note)
+	}
+projects:
+	for pi := range tracker.Projects {
+		p := &tracker.Projects[pi]
+		if *project != "" && p.Name != *project {
+			continue
+		}
+		var drop []int
+		for _, d := range findDuplicates(p, *tolerance) {
+			found++
+			fmt.Printf("'%s':\n", p.Name)
+			show("keep  ", d.keep, p.Logs[d.keep])
+			show("remove", d.drop, p.Logs[d.drop])
+			if !checkLocked(tracker, p.Name, p.Logs[d.drop], *force) {
+				continue
+			}
+			if !all && !ask {
+				fmt.Println("Not removed: stdin is not a terminal; rerun with --yes.")
+				continue
+			}
+			if !all {
+				fmt.Print("Remove? [y/N/a(ll)/q]: ")
+				line, _ := readAnswer(in)
+				switch strings.ToLower(strings.TrimSpace(line)) {
+				case "y":
+				case "a":
+					all = true
+				case "q":
+					drop = nil
+					break projects
+				default:
+					continue
+				}
+			}
+			drop = append(drop, d.drop)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(drop)))
+		for _, i := range drop {
+			p.Logs = append(p.Logs[:i], p.Logs[i+1:]...)
+			removed++
+		}
+		if len(drop) > 0 {
+			recomputeTotal(p)
+		}
+	}
+	if found == 0 {
+		fmt.Println("No duplicates.")
+		return
+	}
+	if removed > 0 {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	fmt.Printf("Found %d duplicates, removed %d.\n", found, removed)
+}
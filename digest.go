@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the mail server digest sends through.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from,omitempty"`
+}
+
+type digestRow struct {
+	Name     string
+	Sessions int
+	Hours    float64
+	Percent  float64
+	Earned   string
+}
+
+type digestDay struct {
+	Date  string
+	Hours float64
+}
+
+type digestData struct {
+	Title    string
+	Period   string
+	Projects []digestRow
+	Days     []digestDay
+	Hours    float64
+	Earned   string
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{"decimal": formatDecimal}).Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2>{{.Title}}</h2>
+<p>{{.Period}}: <b>{{decimal .Hours 2}} hours</b>{{with .Earned}}, {{.}} earned{{end}}</p>
+{{if .Projects}}<table cellpadding="4" style="border-collapse: collapse">
+<tr><th align="left">Project</th><th align="right">Sessions</th><th align="right">Hours</th><th align="right">%</th>{{if .Earned}}<th align="right">Earned</th>{{end}}</tr>
+{{range .Projects}}<tr><td>{{.Name}}</td><td align="right">{{.Sessions}}</td><td align="right">{{decimal .Hours 2}}</td><td align="right">{{decimal .Percent 0}}</td>{{if $.Earned}}<td align="right">{{.Earned}}</td>{{end}}</tr>
+{{end}}</table>
+<h3>By day</h3>
+<table cellpadding="4" style="border-collapse: collapse">
+{{range .Days}}<tr><td>{{.Date}}</td><td align="right">{{decimal .Hours 2}}h</td></tr>
+{{end}}</table>{{else}}<p>Nothing tracked.</p>{{end}}
+</body></html>
+`))
+
+// lastWeek returns Monday to Monday of the week before now's week.
+func lastWeek(now time.Time) (from, to time.Time) {
+	today, _ := parseDate("today", now)
+	to = weekStart(today)
+	return to.AddDate(0, 0, -7), to
+}
+
+func buildDigest(idx *dailyIndex, tracker *TrackerData, from, to, now time.Time) digestData {
+	d := digestData{Title: "ptracker weekly digest", Period: rangeLabel(from, to)}
+	var total time.Duration
+	var earned float64
+	totals := map[string]time.Duration{}
+	for _, p := range tracker.Projects {
+		if p.Name == breakProject {
+			continue
+		}
+		t, n := rangeTotal(idx, p, from, to, now)
+		if t == 0 {
+			continue
+		}
+		totals[p.Name] = t
+		total += t
+		row := digestRow{Name: p.Name, Sessions: n, Hours: t.Hours()}
+		if p.Rate > 0 {
+			row.Earned = formatMoney(earnings(t, p.Rate))
+			earned += earnings(t, p.Rate)
+		}
+		d.Projects = append(d.Projects, row)
+	}
+	for i := range d.Projects {
+		d.Projects[i].Percent = percentShare(totals[d.Projects[i].Name], total)
+	}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		var t time.Duration
+		for _, p := range tracker.Projects {
+			if p.Name != breakProject {
+				dt, _ := rangeTotal(idx, p, day, day.AddDate(0, 0, 1), now)
+				t += dt
+			}
+		}
+		d.Days = append(d.Days, digestDay{day.Format("Mon ") + formatDate(day), t.Hours()})
+	}
+	d.Hours = total.Hours()
+	if earned > 0 {
+		d.Earned = formatMoney(earned)
+	}
+	return d
+}
+
+func sendMail(to, subject, html string) error {
+	c := config.SMTP
+	if c.Host == "" {
+		return fmt.Errorf(`no SMTP server; set "smtp": {"host": ...} in config`)
+	}
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+	from := c.From
+	if from == "" {
+		from = c.Username
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(html, "\n", "\r\n"))
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	return smtp.SendMail(c.Host+":"+strconv.Itoa(port), auth, from, strings.Split(to, ","), msg.Bytes())
+}
+
+func cmdDigest(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	email := fs.String("email", "", "send the digest to this address instead of printing it")
+	discord := fs.Bool("discord", false, "post today's summary (or --from/--to) to the Discord webhook")
+	fromStr := fs.String("from", "", "first day (default: Monday of last week)")
+	toStr := fs.String("to", "", "last day")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	from, to := lastWeek(now)
+	if *discord {
+		from, _ = parseDate("today", now)
+		to = from.AddDate(0, 0, 1)
+	}
+	if *fromStr != "" || *toStr != "" {
+		var err error
+		if from, to, err = parseDateRange(*fromStr, *toStr, now); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if from.IsZero() || to.IsZero() {
+			fmt.Println("--from and --to must be given together.")
+			return
+		}
+	}
+	idx, err := indexFor(dataPath, tracker.Checksum)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	data := buildDigest(idx, tracker, from, to, now)
+	if *discord {
+		if config.Discord.Webhook == "" {
+			fmt.Println(`No Discord webhook; set "discord": {"webhook": ...} in config.`)
+			return
+		}
+		if err := postDiscord(config.Discord, discordSummary(data)); err != nil {
+			fmt.Println("Error posting summary:", err)
+			return
+		}
+		fmt.Println("Summary posted to Discord.")
+		return
+	}
+	var html bytes.Buffer
+	if err := digestTemplate.Execute(&html, data); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *email == "" {
+		fmt.Print(html.String())
+		return
+	}
+	subject := fmt.Sprintf("Time tracked %s: %sh", data.Period, formatDecimal(data.Hours, 1))
+	if err := sendMail(*email, subject, html.String()); err != nil {
+		fmt.Println("Error sending digest:", err)
+		return
+	}
+	fmt.Printf("Digest sent to %s.\n", *email)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DiscordConfig posts a daily summary to a Discord channel webhook.
+type DiscordConfig struct {
+	Webhook string `json:"webhook"`
+	// At is the time of day serve posts the summary (default "18:00").
+	At string `json:"at,omitempty"`
+	// Username is shown as the author instead of the webhook's name.
+	Username string `json:"username,omitempty"`
+}
+
+// discordMax is the longest message content Discord accepts.
+const discordMax = 2000
+
+// discordSummary renders a digest as Discord markdown.
+func discordSummary(d digestData) string {
+	period := d.Period
+	if len(d.Days) == 1 {
+		period = d.Days[0].Date
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** — %s\n", period, formatHM(time.Duration(d.Hours*float64(time.Hour))))
+	if len(d.Projects) == 0 {
+		b.WriteString("Nothing tracked.")
+		return b.String()
+	}
+	for _, p := range d.Projects {
+		fmt.Fprintf(&b, "`%s` %s", formatHM(time.Duration(p.Hours*float64(time.Hour))), p.Name)
+		if p.Earned != "" {
+			fmt.Fprintf(&b, " (%s)", p.Earned)
+		}
+		b.WriteByte('\n')
+	}
+	if d.Earned != "" {
+		fmt.Fprintf(&b, "Earned: %s\n", d.Earned)
+	}
+	s := strings.TrimSuffix(b.String(), "\n")
+	if len(s) > discordMax {
+		s = s[:strings.LastIndexByte(s[:discordMax-4], '\n')] + "\n…"
+	}
+	return s
+}
+
+// postDiscord sends content to a Discord webhook.
+func postDiscord(c DiscordConfig, content string) error {
+	body, err := json.Marshal(struct {
+		Content  string `json:"content"`
+		Username string `json:"username,omitempty"`
+	}{content, c.Username})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("discord webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// discordAt returns when the summary is due on day.
+func discordAt(c DiscordConfig, day time.Time) (time.Time, error) {
+	at := c.At
+	if at == "" {
+		at = "18:00"
+	}
+	t, err := parseDateTime(day.Format(dateLayout)+" "+at, day)
+	if err != nil {
+		return t, fmt.Errorf(`discord "at": %w`, err)
+	}
+	return t, nil
+}
+
+// runDiscord posts the day's summary once its time has come. A daemon
+// started after that time waits for the next day, so restarts don't
+// post twice.
+func (s *apiServer) runDiscord(c DiscordConfig) {
+	last := time.Now().UTC()
+	for range time.Tick(time.Minute) {
+		now := time.Now().UTC()
+		today, _ := parseDate("today", now)
+		at, err := discordAt(c, today)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		if now.Before(at) || !last.Before(at) {
+			continue
+		}
+		last = now
+		tracker, err := s.store.view()
+		if err != nil {
+			log.Println("discord:", err)
+			continue
+		}
+		idx, err := indexFor(s.store.dataPath, tracker.Checksum)
+		if err != nil {
+			log.Println("discord:", err)
+			continue
+		}
+		d := buildDigest(idx, tracker, today, today.AddDate(0, 0, 1), now)
+		if err := postDiscord(c, discordSummary(d)); err != nil {
+			log.Println("discord:", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+func cmdDoctor(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	overlaps := fs.Bool("overlaps", false, "list overlapping entries within each project")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if !*overlaps {
+		fmt.Println("Nothing to check. Use --overlaps.")
+		return
+	}
+	count := 0
+	for _, p := range tracker.Projects {
+		idx := make([]int, len(p.Logs))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool { return p.Logs[idx[a]].Start.Before(p.Logs[idx[b]].Start) })
+		// track the entry reaching furthest so far so nested overlaps are caught
+		furthest := -1
+		var furthestEnd time.Time
+		for _, i := range idx {
+			e := p.Logs[i]
+			end := e.End
+			if end.IsZero() {
+				end = now
+			}
+			if furthest >= 0 && e.Start.Before(furthestEnd) {
+				fmt.Printf("%s: #%d (%s) overlaps #%d (until %s)\n", p.Name, i+1, e.Start.Format("2006-01-02 15:04:05"), furthest+1, furthestEnd.Format("2006-01-02 15:04:05"))
+				count++
+			}
+			if end.After(furthestEnd) {
+				furthest, furthestEnd = i, end
+			}
+		}
+	}
+	if count == 0 {
+		fmt.Println("No overlaps found.")
+	}
+}
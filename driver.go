@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Storage is where a profile's TrackerData lives. Command logic only sees
+// TrackerData; loadTracker and writeTracker go through the driver, which
+// handles encoding and persistence.
+type Storage interface {
+	// Load returns the stored data, or empty data if there is none yet.
+	Load() (*TrackerData, error)
+	// Save replaces the stored data with tracker.
+	Save(tracker *TrackerData) error
+	// Watch returns a revision that changes whenever the stored data
+	// does, so a caller holding a snapshot knows when to reload it.
+	Watch() (string, error)
+	// Lock takes the exclusive lock shared by the CLI and the daemon for
+	// a load-modify-save cycle.
+	Lock() (unlock func(), err error)
+}
+
+// storageDrivers open a Storage for a profile's data path, by name.
+var storageDrivers = map[string]func(dataPath string) Storage{}
+
+func registerStorage(name string, open func(dataPath string) Storage) {
+	storageDrivers[name] = open
+}
+
+// openStorage opens dataPath with the driver named by config "storage",
+// the data file ("file") by default.
+func openStorage(dataPath string) (Storage, error) {
+	name := config.Storage
+	if name == "" {
+		name = "file"
+	}
+	open, ok := storageDrivers[name]
+	if !ok {
+		var names []string
+		for n := range storageDrivers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown storage driver '%s' (available: %s)", name, strings.Join(names, ", "))
+	}
+	return open(dataPath), nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// recomputeTotal rebuilds TotalTime from the project's closed entries.
+func recomputeTotal(p *Project) {
+	p.TotalTime = 0
+	for _, e := range p.Logs {
+		if !e.End.IsZero() {
+			p.TotalTime += e.Duration(e.End)
+		}
+	}
+}
+
+// findOverlap returns the index of an entry in p that overlaps
+// [start, end), ignoring index skip, or -1. Open entries run until now.
+func findOverlap(p *Project, start, end time.Time, skip int, now time.Time) int {
+	for i, e := range p.Logs {
+		if i == skip {
+			continue
+		}
+		eEnd := e.End
+		if eEnd.IsZero() {
+			eEnd = now
+		}
+		if start.Before(eEnd) && e.Start.Before(end) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkOverlap applies the configured overlap policy and reports whether
+// the operation may proceed.
+func checkOverlap(p *Project, start, end time.Time, skip int, now time.Time) bool {
+	i := findOverlap(p, start, end, skip, now)
+	if i < 0 {
+		return true
+	}
+	if config.Overlap == "warn" {
+		fmt.Printf("Warning: overlaps '%s' #%d.\n", p.Name, i+1)
+		return true
+	}
+	fmt.Printf("Overlaps '%s' #%d. Set \"overlap\": \"warn\" in config to allow.\n", p.Name, i+1)
+	return false
+}
+
+// insertEntry inserts e in start order, keeping an open session last:
+// running() only looks there, so an open e always goes at the end even if
+// it starts before closed entries (possible with "overlap": "warn").
+func insertEntry(p *Project, e LogEntry) int {
+	i := len(p.Logs)
+	if !e.End.IsZero() {
+		for j, x := range p.Logs {
+			if x.Start.After(e.Start) {
+				i = j
+				break
+			}
+		}
+	}
+	if i == len(p.Logs) && i > 0 && p.Logs[i-1].End.IsZero() && !e.End.IsZero() {
+		i--
+	}
+	p.Logs = append(p.Logs, LogEntry{})
+	copy(p.Logs[i+1:], p.Logs[i:])
+	p.Logs[i] = e
+	return i
+}
+
+func cmdAdd(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	startStr := fs.String("start", "", "entry start time")
+	endStr := fs.String("end", "", "entry end time")
+	note := fs.String("note", "", "describe the session")
+	var tags listFlag
+	fs.Var(&tags, "tag", "tag the entry (repeatable)")
+	force := fs.Bool("force", false, "add even inside a locked period")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 || *startStr == "" || *endStr == "" {
+		fmt.Println("Project name, --start and --end required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, pos[0])
+	if p == nil {
+		fmt.Println(notFoundError(pos[0]))
+		return
+	}
+	start, err := parseDateTime(*startStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	end, err := parseDateTime(*endStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if !end.After(start) {
+		fmt.Println("End must be after start.")
+		return
+	}
+	if !checkLocked(tracker, p.Name, LogEntry{Start: start}, *force) || !checkOverlap(p, start, end, -1, now) {
+		return
+	}
+	i := insertEntry(p, LogEntry{Start: start, End: end, Note: *note, Tags: tags, User: currentUser()})
+	recomputeTotal(p)
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Added '%s' #%d: %.2fmin\n", p.Name, i+1, end.Sub(start).Minutes())
+}
+
+func cmdEdit(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	startStr := fs.String("start", "", "new start time")
+	endStr := fs.String("end", "", "new end time")
+	note := fs.String("note", "", "new note")
+	force := fs.Bool("force", false, "edit even a locked entry")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 2 {
+		fmt.Println("Project name and entry number required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, pos[0])
+	if p == nil {
+		fmt.Println(notFoundError(pos[0]))
+		return
+	}
+	i, err := entryIndex(p, pos[1])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	e := p.Logs[i]
+	if !checkLocked(tracker, p.Name, e, *force) {
+		return
+	}
+	// a bare time is on the entry's own day, not today: the start's, and
+	// the end's unless the start moved
+	endDay := e.End
+	if *startStr != "" {
+		if e.Start, err = parseDateTime(*startStr, e.Start); err != nil {
+			fmt.Println(err)
+			return
+		}
+		endDay = e.Start
+	}
+	if endDay.IsZero() {
+		endDay = e.Start
+	}
+	if *endStr != "" {
+		if e.End, err = parseDateTime(*endStr, endDay); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if *note != "" {
+		e.Note = *note
+	}
+	end := e.End
+	if end.IsZero() {
+		end = now
+	}
+	if !end.After(e.Start) {
+		fmt.Println("End must be after start.")
+		return
+	}
+	if end.After(now) {
+		fmt.Println("End can't be in the future.")
+		return
+	}
+	if !checkLocked(tracker, p.Name, e, *force) || !checkOverlap(p, e.Start, end, i, now) {
+		return
+	}
+	// re-insert so a moved start keeps the entries in order
+	p.Logs = append(p.Logs[:i], p.Logs[i+1:]...)
+	i = insertEntry(p, e)
+	recomputeTotal(p)
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Updated '%s' #%d.\n", p.Name, i+1)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInsertEntryKeepsOpenSessionLast(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 3, 10, h, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		e    LogEntry
+		want int
+	}{
+		{"closed before the rest", LogEntry{Start: at(7), End: at(8)}, 0},
+		{"closed between", LogEntry{Start: at(10), End: at(11)}, 1},
+		{"closed after the open one starts", LogEntry{Start: at(13), End: at(14)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Project{Logs: []LogEntry{{Start: at(8), End: at(9)}, {Start: at(11), End: at(12)}, {Start: at(12)}}}
+			if got := insertEntry(p, tt.e); got != tt.want {
+				t.Errorf("inserted at %d, want %d", got, tt.want)
+			}
+			if p.running() == nil {
+				t.Error("open session is no longer last")
+			}
+		})
+	}
+}
+
+// Moving a running session's start before a closed entry, allowed with
+// "overlap": "warn", must leave it running.
+func TestEditRunningStartBeforeClosedEntry(t *testing.T) {
+	saved := config.Overlap
+	config.Overlap = "warn"
+	defer func() { config.Overlap = saved }()
+	dataPath := filepath.Join(t.TempDir(), jsonDataFile)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tracker := &TrackerData{Projects: []Project{{Name: "a", Logs: []LogEntry{
+		{Start: now.Add(-3 * time.Hour), End: now.Add(-2 * time.Hour)},
+		{Start: now.Add(-time.Hour)},
+	}}}}
+	recomputeTotal(&tracker.Projects[0])
+
+	cmdEdit(dataPath, tracker, []string{"a", "2", "--start", "08:00"}, now)
+
+	p := tracker.Projects[0]
+	e := p.running()
+	if e == nil {
+		t.Fatal("session is no longer running after the edit")
+	}
+	if want := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC); !e.Start.Equal(want) {
+		t.Errorf("start = %s, want %s", e.Start, want)
+	}
+	if _, _, err := stopSession(tracker, "a", "", now); err != nil {
+		t.Errorf("stop after the edit: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// lockedPeriod freezes the entries that start within [From, To), for one
+// project or all of them, or with Invoice set only the entries and
+// expenses billed by that invoice (From and To are then just its range).
+// Adding, editing or deleting them then needs --force, so submitted
+// timesheets can't change silently.
+type lockedPeriod struct {
+	Project string    `json:"project,omitempty"`
+	From    time.Time `json:"from,omitzero"`
+	To      time.Time `json:"to,omitzero"`
+	Invoice string    `json:"invoice,omitempty"`
+	Locked  time.Time `json:"locked"`
+}
+
+func (lp lockedPeriod) String() string {
+	s := rangeLabel(lp.From, lp.To)
+	if lp.Project != "" {
+		s = lp.Project + ", " + s
+	}
+	if lp.Invoice != "" {
+		s = "invoice " + lp.Invoice + ": " + s
+	}
+	return s
+}
+
+// lockedAt returns the lock covering e in project, if any. A new entry
+// only needs its Start set.
+func lockedAt(tracker *TrackerData, project string, e LogEntry) *lockedPeriod {
+	for i, lp := range tracker.Locks {
+		if lp.Project != "" && lp.Project != project {
+			continue
+		}
+		if lp.Invoice != "" {
+			if e.Invoice == lp.Invoice {
+				return &tracker.Locks[i]
+			}
+			continue
+		}
+		if inRange(e.Start, lp.From, lp.To) {
+			return &tracker.Locks[i]
+		}
+	}
+	return nil
+}
+
+// checkLocked reports whether entry e of project may be changed, printing
+// why not unless force is set.
+func checkLocked(tracker *TrackerData, project string, e LogEntry, force bool) bool {
+	lp := lockedAt(tracker, project, e)
+	if lp == nil || force {
+		return true
+	}
+	fmt.Printf("Entry on %s is locked (%s). Use --force to change it.\n", e.Start.Format(dateLayout), lp)
+	return false
+}
+
+// expenseEntry stands in for an expense in lock checks, which match it
+// by date and invoice like an entry.
+func expenseEntry(x Expense) LogEntry {
+	return LogEntry{Start: x.Date, Invoice: x.Invoice}
+}
+
+// lockedChanges counts the entries in locked periods that replacing the
+// data with in would change, remove or add, and the locks it would drop.
+func lockedChanges(tracker, in *TrackerData) (entries, locks int) {
+	type version struct {
+		project string
+		entry   LogEntry
+	}
+	byID := func(t *TrackerData) map[string]version {
+		m := map[string]version{}
+		for _, p := range t.Projects {
+			for _, e := range p.Logs {
+				id := e.ID
+				if id == "" {
+					id = entryDigest(e)
+				}
+				m[id] = version{p.Name, e}
+			}
+		}
+		return m
+	}
+	before, after := byID(tracker), byID(in)
+	for id, old := range before {
+		v, ok := after[id]
+		changed := !ok || v.project != old.project || entryDigest(v.entry) != entryDigest(old.entry)
+		if changed && (lockedAt(tracker, old.project, old.entry) != nil || ok && lockedAt(tracker, v.project, v.entry) != nil) {
+			entries++
+		}
+	}
+	for id, v := range after {
+		if _, ok := before[id]; !ok && lockedAt(tracker, v.project, v.entry) != nil {
+			entries++
+		}
+	}
+	for _, lp := range tracker.Locks {
+		if !slices.Contains(in.Locks, lp) {
+			locks++
+		}
+	}
+	return entries, locks
+}
+
+// checkReplaceLocked reports whether the data may be replaced with in,
+// printing what locked data would change unless force is set.
+func checkReplaceLocked(tracker, in *TrackerData, force bool) bool {
+	entries, locks := lockedChanges(tracker, in)
+	if entries == 0 && locks == 0 || force {
+		return true
+	}
+	fmt.Printf("That changes %d entries in locked periods and removes %d locks. Use --force to replace them anyway.\n", entries, locks)
+	return false
+}
+
+// cutForce removes a --force argument from commands that take free text.
+func cutForce(args []string) ([]string, bool) {
+	var rest []string
+	force := false
+	for _, a := range args {
+		if a == "--force" || a == "-force" {
+			force = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, force
+}
+
+func cmdLock(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("lock", flag.ContinueOnError)
+	fromStr := fs.String("from", "", "first locked day")
+	toStr := fs.String("to", "", "last locked day")
+	project := fs.String("project", "", "lock only this project")
+	invoice := fs.String("invoice", "", "lock the entries billed by this invoice")
+	yes := fs.Bool("yes", false, "remove: unlock without asking")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) > 0 && pos[0] == "list" || len(pos) == 0 && *fromStr == "" && *toStr == "" && *invoice == "" {
+		if len(tracker.Locks) == 0 {
+			fmt.Println("No locked periods.")
+			return
+		}
+		for i, lp := range tracker.Locks {
+			fmt.Printf("%d. %s\n", i+1, lp)
+		}
+		return
+	}
+	if len(pos) > 0 && pos[0] == "remove" {
+		var n int
+		if len(pos) < 2 {
+			fmt.Println("Lock number required.")
+			return
+		}
+		if _, err := fmt.Sscan(pos[1], &n); err != nil || n < 1 || n > len(tracker.Locks) {
+			fmt.Printf("Invalid lock '%s' (1-%d).\n", pos[1], len(tracker.Locks))
+			return
+		}
+		lp := tracker.Locks[n-1]
+		if !confirm(fmt.Sprintf("Unlock %s? Its entries can then change without --force.", lp), *yes) {
+			return
+		}
+		tracker.Locks = append(tracker.Locks[:n-1], tracker.Locks[n:]...)
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+		fmt.Printf("Unlocked %s.\n", lp)
+		return
+	}
+	lp := lockedPeriod{Project: *project, Locked: now}
+	if *invoice != "" {
+		inv := findInvoice(tracker, *invoice)
+		if inv == nil {
+			fmt.Printf("Invoice '%s' not found.\n", *invoice)
+			return
+		}
+		lp.Project, lp.From, lp.To, lp.Invoice = inv.Project, inv.From, inv.To, inv.Number
+	} else {
+		if *fromStr == "" || *toStr == "" {
+			fmt.Println("--from and --to required.")
+			return
+		}
+		if lp.From, lp.To, err = parseDateRange(*fromStr, *toStr, now); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if lp.Project != "" {
+			p := lookupProject(tracker, lp.Project)
+			if p == nil {
+				fmt.Println(notFoundError(lp.Project))
+				return
+			}
+			lp.Project = p.Name
+		}
+	}
+	tracker.Locks = append(tracker.Locks, lp)
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Locked %s.\n", lp)
+}
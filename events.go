@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+type sessionEvent struct {
+	Event           string    `json:"event"`
+	Project         string    `json:"project"`
+	Time            time.Time `json:"time"`
+	Note            string    `json:"note,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+
+	entry LogEntry
+}
+
+func activeEntries(tracker *TrackerData) map[string]LogEntry {
+	active := map[string]LogEntry{}
+	for _, p := range tracker.Projects {
+		if e := p.running(); e != nil {
+			active[p.Name] = *e
+		}
+	}
+	return active
+}
+
+// sessionEvents compares the active sessions before and after a save and
+// returns a start or stop event for each project that changed.
+func sessionEvents(before, after *TrackerData, now time.Time) []sessionEvent {
+	was, is := activeEntries(before), activeEntries(after)
+	var events []sessionEvent
+	for _, p := range after.Projects {
+		e, active := is[p.Name]
+		_, wasActive := was[p.Name]
+		switch {
+		case active && !wasActive:
+			events = append(events, sessionEvent{Event: "start", Project: p.Name, Time: e.Start, Note: e.Note, Tags: e.Tags, entry: e})
+		case !active && wasActive && len(p.Logs) > 0:
+			last := p.Logs[len(p.Logs)-1]
+			events = append(events, sessionEvent{Event: "stop", Project: p.Name, Time: last.End,
+				Note: last.Note, Tags: last.Tags, DurationSeconds: last.Duration(now).Seconds(), entry: last})
+		}
+	}
+	return events
+}
+
+// wantsSessionEvents reports whether saves need to work out which
+// sessions started and stopped, i.e. whether anything listens for them.
+func wantsSessionEvents(tracker *TrackerData) bool {
+	if config.MQTT.Broker != "" || config.OTLP.Endpoint != "" || config.Webhook != "" {
+		return true
+	}
+	for _, p := range tracker.Projects {
+		if p.Meta[metaWebhook] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// announceSessions passes the sessions started and stopped by a save on
+// to the configured webhooks, MQTT broker and OTLP collector.
+func announceSessions(before, after *TrackerData, now time.Time) {
+	events := sessionEvents(before, after, now)
+	for _, ev := range events {
+		if url := projectSetting(findProject(after, ev.Project), metaWebhook, config.Webhook); url != "" {
+			if err := postWebhook(url, ev); err != nil {
+				log.Printf("webhook %s: %v", ev.Project, err)
+			}
+		}
+	}
+	if config.MQTT.Broker != "" {
+		publishSessionEvents(events, after, now)
+	}
+	if config.OTLP.Endpoint != "" {
+		sessions := map[string][]LogEntry{}
+		for _, ev := range events {
+			if ev.Event == "stop" {
+				sessions[ev.Project] = append(sessions[ev.Project], ev.entry)
+			}
+		}
+		if len(sessions) > 0 {
+			if err := exportSpans(config.OTLP, sessions); err != nil {
+				log.Println("otlp:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expense is a cost billed to a project alongside its time.
+type Expense struct {
+	// ID and Modified let sync merge expenses as it does entries.
+	ID       string    `json:"id,omitempty"`
+	Modified time.Time `json:"modified,omitzero"`
+
+	Date    time.Time `json:"date"`
+	Amount  float64   `json:"amount"`
+	Note    string    `json:"note,omitempty"`
+	Invoice string    `json:"invoice,omitempty"`
+}
+
+func (p Project) expenseTotal(from, to time.Time) float64 {
+	var sum float64
+	for _, x := range p.Expenses {
+		if inRange(x.Date, from, to) {
+			sum += x.Amount
+		}
+	}
+	return sum
+}
+
+func cmdExpense(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("expense", flag.ContinueOnError)
+	dateStr := fs.String("date", "today", "date of the expense")
+	force := fs.Bool("force", false, "change even inside a locked period")
+	yes := fs.Bool("yes", false, "rm: remove without asking")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Usage: expense [add|list|rm] [project] ...")
+		return
+	}
+	switch pos[0] {
+	case "add":
+		if len(pos) < 3 {
+			fmt.Println("Usage: expense add [project] [amount] [note]")
+			return
+		}
+		p := lookupProject(tracker, pos[1])
+		if p == nil {
+			fmt.Println(notFoundError(pos[1]))
+			return
+		}
+		amount, err := strconv.ParseFloat(pos[2], 64)
+		if err != nil || amount <= 0 {
+			fmt.Printf("Invalid amount '%s'.\n", pos[2])
+			return
+		}
+		date, err := parseDate(*dateStr, now)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !checkLocked(tracker, p.Name, LogEntry{Start: date}, *force) {
+			return
+		}
+		p.Expenses = append(p.Expenses, Expense{Date: date, Amount: amount, Note: strings.Join(pos[3:], " ")})
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+		fmt.Printf("Added expense %s to '%s'.\n", formatMoney(amount), p.Name)
+	case "list":
+		var total float64
+		found := false
+		for _, p := range tracker.Projects {
+			if len(pos) > 1 && p.Name != pos[1] {
+				continue
+			}
+			for i, x := range p.Expenses {
+				billed := ""
+				if x.Invoice != "" {
+					billed = " [" + x.Invoice + "]"
+				}
+				fmt.Printf("%-16s %2d | %s | %10s | %s%s\n", p.Name, i+1, x.Date.Format(dateLayout), formatMoney(x.Amount), x.Note, billed)
+				total += x.Amount
+				found = true
+			}
+		}
+		if !found {
+			fmt.Println("No expenses.")
+			return
+		}
+		fmt.Printf("Total: %s\n", formatMoney(total))
+	case "rm":
+		if len(pos) < 3 {
+			fmt.Println("Usage: expense rm [project] [#]")
+			return
+		}
+		p := lookupProject(tracker, pos[1])
+		if p == nil {
+			fmt.Println(notFoundError(pos[1]))
+			return
+		}
+		n, err := strconv.Atoi(pos[2])
+		if err != nil || n < 1 || n > len(p.Expenses) {
+			fmt.Printf("Invalid expense '%s' for '%s' (1-%d).\n", pos[2], p.Name, len(p.Expenses))
+			return
+		}
+		x := p.Expenses[n-1]
+		if !checkLocked(tracker, p.Name, expenseEntry(x), *force) {
+			return
+		}
+		fmt.Printf("#%d %s %s %s\n", n, formatDate(x.Date), formatMoney(x.Amount), x.Note)
+		if !confirm(fmt.Sprintf("Remove expense #%d from '%s'?", n, p.Name), *yes) {
+			return
+		}
+		p.Expenses = append(p.Expenses[:n-1], p.Expenses[n:]...)
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+		fmt.Printf("Removed expense #%d from '%s'.\n", n, p.Name)
+	default:
+		fmt.Printf("Unknown expense command '%s'.\n", pos[0])
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// exportOptions are the flags shared by every export format.
+type exportOptions struct {
+	from, to time.Time
+	project  string
+	out      string
+	now      time.Time
+}
+
+// selectedProjects returns the projects an export covers, leaving out
+// breaks unless asked for by name.
+func (o exportOptions) selectedProjects(tracker *TrackerData) []Project {
+	var out []Project
+	for _, p := range tracker.Projects {
+		if o.project != "" && p.Name != o.project {
+			continue
+		}
+		if o.project == "" && p.Name == breakProject {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// entries returns p's entries starting within the export range.
+func (o exportOptions) entries(p Project) []LogEntry {
+	var out []LogEntry
+	for _, e := range p.Logs {
+		if inRange(e.Start, o.from, o.to) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// exportOutput opens the destination of a streamed export: the -o file
+// or stdout, gzip-compressed when compress is set. close must be called
+// to flush it.
+func exportOutput(out string, compress bool) (w io.Writer, close func() error, err error) {
+	var dst io.WriteCloser = nopWriteCloser{os.Stdout}
+	if out != "" {
+		if dst, err = os.Create(out); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !compress {
+		return dst, dst.Close, nil
+	}
+	gz := gzip.NewWriter(dst)
+	return gz, func() error {
+		if err := gz.Close(); err != nil {
+			dst.Close()
+			return err
+		}
+		return dst.Close()
+	}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// openMaybeGzip opens a file for reading, decompressing it if it starts
+// with the gzip magic number.
+func openMaybeGzip(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gz, f}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, f}, nil
+}
+
+// exportCSV writes one record per entry in the layout 'import csv' reads.
+func exportCSV(w io.Writer, tracker *TrackerData, opts exportOptions) (int, error) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"project", "start", "end", "note", "user"})
+	n := 0
+	for _, p := range opts.selectedProjects(tracker) {
+		for _, e := range p.Logs {
+			if !inRange(e.Start, opts.from, opts.to) || e.End.IsZero() {
+				continue
+			}
+			cw.Write([]string{p.Name, e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339), e.Note, e.User})
+			n++
+		}
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+func cmdExport(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fromStr := fs.String("from", "", "first day to export")
+	toStr := fs.String("to", "", "last day to export")
+	project := fs.String("project", "", "export only this project")
+	out := fs.String("o", "", "output file or directory")
+	by := fs.String("by", "project", "obsidian: one file per project or per day")
+	tmpl := fs.String("template", "", "render entries through this text/template file")
+	full := fs.Bool("full", false, "json: the whole dataset, for backup or migration")
+	compress := fs.Bool("compress", false, "gzip the output of json, csv and template exports")
+	anonymize := fs.Bool("anonymize", false, "hash project, user and tag names and drop notes, for sharing")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 && *tmpl == "" {
+		fmt.Println("Export format required: export [obsidian|xlsx|json|csv] ... or export --template FILE")
+		return
+	}
+	opts := exportOptions{project: *project, out: *out, now: now}
+	if opts.from, opts.to, err = parseDateRange(*fromStr, *toStr, now); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if opts.project != "" {
+		p := lookupProject(tracker, opts.project)
+		if p == nil {
+			fmt.Println(notFoundError(opts.project))
+			return
+		}
+		opts.project = p.Name
+	}
+	if *anonymize {
+		a := newAnonymizer()
+		tracker = a.anonymize(tracker)
+		if opts.project != "" {
+			opts.project = a.project(opts.project)
+		}
+	}
+	if *compress && opts.out != "" && !strings.HasSuffix(opts.out, ".gz") {
+		opts.out += ".gz"
+	}
+
+	format := "template"
+	if *tmpl == "" {
+		format = pos[0]
+	}
+	switch format {
+	case "obsidian":
+		err = exportObsidian(tracker, opts, *by)
+	case "xlsx":
+		err = exportXLSX(tracker, opts)
+	case "json", "csv", "template":
+		var w io.Writer
+		var closeOut func() error
+		if w, closeOut, err = exportOutput(opts.out, *compress); err != nil {
+			break
+		}
+		var n int
+		switch format {
+		case "json":
+			n, err = exportJSON(w, tracker, opts, *full)
+		case "csv":
+			n, err = exportCSV(w, tracker, opts)
+		default:
+			err = exportTemplate(w, tracker, opts, *tmpl)
+		}
+		if cerr := closeOut(); err == nil {
+			err = cerr
+		}
+		if err == nil && opts.out != "" && format != "template" {
+			fmt.Printf("Exported %d entries to %s.\n", n, opts.out)
+		}
+	default:
+		fmt.Printf("Unknown export format '%s'.\n", format)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error exporting:", err)
+	}
+}
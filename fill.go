@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dayGaps returns the untracked stretches between the first and last
+// tracked time of the day, across all projects, that are at least minGap
+// long.
+func dayGaps(tracker *TrackerData, day time.Time, minGap time.Duration, now time.Time) [][2]time.Time {
+	dayEnd := day.AddDate(0, 0, 1)
+	var ivs [][2]time.Time
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			for _, iv := range e.intervals(now) {
+				if iv[1].After(day) && iv[0].Before(dayEnd) {
+					ivs = append(ivs, iv)
+				}
+			}
+		}
+	}
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i][0].Before(ivs[j][0]) })
+	var gaps [][2]time.Time
+	var covered time.Time
+	for i, iv := range ivs {
+		if i > 0 && iv[0].Sub(covered) >= minGap {
+			gaps = append(gaps, [2]time.Time{covered, iv[0]})
+		}
+		if iv[1].After(covered) {
+			covered = iv[1]
+		}
+	}
+	return gaps
+}
+
+// cmdFill walks the day's untracked gaps and asks what each one was.
+func cmdFill(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("fill", flag.ContinueOnError)
+	dateStr := fs.String("date", "today", "day to fill")
+	minGap := fs.Duration("min", 5*time.Minute, "ignore gaps shorter than this")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	day, err := parseDate(*dateStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	gaps := dayGaps(tracker, day, *minGap, now)
+	if len(gaps) == 0 {
+		fmt.Printf("No gaps on %s.\n", day.Format(dateLayout))
+		return
+	}
+	in := bufio.NewReader(os.Stdin)
+	filled := 0
+gaps:
+	for _, g := range gaps {
+		for {
+			fmt.Printf("%s-%s (%s) untracked. Project, b for break, Enter to skip, q to quit: ",
+				g[0].Format("15:04"), g[1].Format("15:04"), formatHM(g[1].Sub(g[0])))
+			line, err := readAnswer(in)
+			answer := strings.TrimSpace(line)
+			if answer == "" {
+				if err != nil {
+					fmt.Println()
+					break gaps
+				}
+				continue gaps
+			}
+			if answer == "q" {
+				break gaps
+			}
+			name, note, _ := strings.Cut(answer, " ")
+			var p *Project
+			if name == "b" || name == breakProject {
+				p = ensureBreakProject(tracker)
+			} else if p = lookupProject(tracker, name); p == nil {
+				fmt.Println(notFoundError(name))
+				continue
+			}
+			if !checkLocked(tracker, p.Name, LogEntry{Start: g[0]}, false) {
+				continue gaps
+			}
+			insertEntry(p, LogEntry{Start: g[0], End: g[1], Note: strings.TrimSpace(note), User: currentUser()})
+			recomputeTotal(p)
+			filled++
+			continue gaps
+		}
+	}
+	if filled == 0 {
+		fmt.Println("Nothing filled.")
+		return
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Filled %d gaps.\n", filled)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FiscalConfig defines the periods of 'report --group-by period' and
+// quarter. YearStart is MM-DD (default 01-01). Pattern is empty for
+// calendar-month periods, or a week pattern such as "4-4-5" that is
+// repeated for each quarter; week-based years start on the configured
+// week start day on or before YearStart, and the last period absorbs the
+// occasional 53rd week.
+type FiscalConfig struct {
+	YearStart string `json:"year_start,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+}
+
+type fiscalCalendar struct {
+	month time.Month
+	day   int
+	weeks []int
+}
+
+func parseFiscal(c FiscalConfig) (fiscalCalendar, error) {
+	fc := fiscalCalendar{month: time.January, day: 1}
+	if c.YearStart != "" {
+		t, err := time.Parse("01-02", c.YearStart)
+		if err != nil {
+			return fc, fmt.Errorf("fiscal year_start %q: expected MM-DD", c.YearStart)
+		}
+		fc.month, fc.day = t.Month(), t.Day()
+	}
+	if c.Pattern != "" {
+		sum := 0
+		for _, s := range strings.Split(c.Pattern, "-") {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return fc, fmt.Errorf("fiscal pattern %q: expected weeks per period, e.g. 4-4-5", c.Pattern)
+			}
+			fc.weeks = append(fc.weeks, n)
+			sum += n
+		}
+		if sum != 13 {
+			return fc, fmt.Errorf("fiscal pattern %q: a quarter must have 13 weeks, not %d", c.Pattern, sum)
+		}
+	}
+	return fc, nil
+}
+
+// yearStart returns when the fiscal year that starts in calendar year y
+// begins.
+func (fc fiscalCalendar) yearStart(y int) time.Time {
+	t := time.Date(y, fc.month, fc.day, 0, 0, 0, 0, time.UTC)
+	if fc.weeks != nil {
+		t = weekStart(t)
+	}
+	return t
+}
+
+// periods returns the start of each period of the fiscal year starting
+// in calendar year y, followed by the next year's start.
+func (fc fiscalCalendar) periods(y int) []time.Time {
+	start, next := fc.yearStart(y), fc.yearStart(y+1)
+	var out []time.Time
+	if fc.weeks == nil {
+		for i := range 12 {
+			out = append(out, start.AddDate(0, i, 0))
+		}
+		return append(out, next)
+	}
+	t := start
+	for range 4 {
+		for _, w := range fc.weeks {
+			out = append(out, t)
+			t = t.AddDate(0, 0, 7*w)
+		}
+	}
+	return append(out, next)
+}
+
+// period locates day: the fiscal year's first calendar year, and the
+// 1-based period number with its bounds.
+func (fc fiscalCalendar) period(day time.Time) (year, n int, start, end time.Time) {
+	year = day.Year()
+	if day.Before(fc.yearStart(year)) {
+		year--
+	}
+	ps := fc.periods(year)
+	for i := 0; i < len(ps)-1; i++ {
+		if !day.Before(ps[i]) && day.Before(ps[i+1]) {
+			return year, i + 1, ps[i], ps[i+1]
+		}
+	}
+	return year, len(ps) - 1, ps[len(ps)-2], ps[len(ps)-1]
+}
+
+func (fc fiscalCalendar) yearLabel(year int) string {
+	if fc.month == time.January && fc.day == 1 && fc.weeks == nil {
+		return fmt.Sprintf("FY%d", year)
+	}
+	return fmt.Sprintf("FY%d/%02d", year, (year+1)%100)
+}
+
+// quarter locates day's fiscal quarter, three periods long.
+func (fc fiscalCalendar) quarter(day time.Time) (year, q int, start, end time.Time) {
+	year, n, _, _ := fc.period(day)
+	ps := fc.periods(year)
+	q = (n-1)/3 + 1
+	return year, q, ps[(q-1)*3], ps[q*3]
+}
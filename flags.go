@@ -0,0 +1,20 @@
+package main
+
+import "flag"
+
+// parseFlags parses fs from args while allowing flags and positional
+// arguments to be interleaved, e.g. `set my_website --color cyan`.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
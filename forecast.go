@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// projectMonth extrapolates time tracked so far this month at the same
+// pace to the end of the month.
+func projectMonth(sofar time.Duration, from, to, now time.Time) time.Duration {
+	elapsed := now.Sub(from)
+	if elapsed <= 0 {
+		return sofar
+	}
+	return time.Duration(float64(sofar) * float64(to.Sub(from)) / float64(elapsed))
+}
+
+// reportMonth prints each project's time this month and a forecast for
+// the whole month at the current pace.
+func reportMonth(idx *dailyIndex, projects []Project, now time.Time) {
+	from, to, _ := parsePeriod("this-month", now)
+	days := to.Sub(from).Hours() / 24
+	fmt.Println("===================================================================")
+	fmt.Printf("Month forecast: %s (day %d of %.0f)\n", from.Format("January 2006"), now.Day(), days)
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %10s | %10s | %10s\n", "Project", "So far", "Per day", "Forecast")
+	fmt.Println("-----------------|------------|------------|-----------")
+	var sofar time.Duration
+	for _, p := range projects {
+		t, _ := rangeTotal(idx, p, from, to, now)
+		if t == 0 {
+			continue
+		}
+		sofar += t
+		f := projectMonth(t, from, to, now)
+		fmt.Printf("%s | %10s | %10s | %10s\n", projectLabel(p, 16), formatHM(t), formatHM(time.Duration(float64(f)/days)), formatHM(f))
+	}
+	f := projectMonth(sofar, from, to, now)
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("%-16s | %10s | %10s | %10s\n", "Total", formatHM(sofar), formatHM(time.Duration(float64(f)/days)), formatHM(f))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// cmdGen writes synthetic data to a new file for profiling, reproducing
+// performance reports and demos. The file is the same for the same flags;
+// --end today trades that for recent entries.
+func cmdGen(args []string, now time.Time) {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	projects := fs.Int("projects", 50, "number of projects")
+	entries := fs.Int("entries", 100000, "number of entries across all projects")
+	seed := fs.Int64("seed", 42, "random seed")
+	endStr := fs.String("end", "2025-01-01", "date the newest entries end before")
+	force := fs.Bool("force", false, "overwrite an existing file")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(rest) != 1 {
+		fmt.Println("Usage: ptracker gen [--projects N] [--entries N] [--seed N] [--end DATE] FILE")
+		return
+	}
+	if *projects < 1 || *entries < 0 {
+		fmt.Println("--projects must be positive and --entries not negative.")
+		return
+	}
+	end, err := parseDate(*endStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	filename := rest[0]
+	if _, err := os.Stat(filename); err == nil && !*force {
+		fmt.Printf("%s already exists (--force to overwrite).\n", filename)
+		return
+	}
+	tracker := synthTracker(*projects, *entries, *seed, end)
+	if err := writeTracker(filename, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Wrote %d projects and %d entries to %s.\n", *projects, *entries, filename)
+}
@@ -0,0 +1,35 @@
+package main
+
+import "strings"
+
+// globalFlags are accepted anywhere on the command line and stripped
+// before the command's own arguments are parsed.
+type globalFlags struct {
+	profile  string
+	readOnly bool
+	clock    string
+}
+
+func extractGlobalFlags(args []string) (globalFlags, []string) {
+	var g globalFlags
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--profile" || a == "-profile":
+			if i+1 < len(args) {
+				g.profile = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(a, "--profile="):
+			g.profile = strings.TrimPrefix(a, "--profile=")
+		case a == "--read-only" || a == "-read-only":
+			g.readOnly = true
+		case a == "--12h" || a == "--24h":
+			g.clock = strings.TrimPrefix(a, "--")
+		default:
+			rest = append(rest, a)
+		}
+	}
+	return g, rest
+}
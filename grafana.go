@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The Grafana endpoints speak the SimpleJSON datasource protocol (GET /
+// to test, POST /search, POST /query). GET /query takes the same query
+// as URL parameters for the Infinity datasource.
+
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs int64 `json:"intervalMs"`
+	Targets    []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// maxBuckets bounds a series' length; longer ranges get wider buckets.
+const maxBuckets = 5000
+
+// bucketHours returns [hours, bucket start in Unix ms] pairs for p's
+// tracked time in [from, to), in buckets of step.
+func bucketHours(p Project, from, to time.Time, step time.Duration, now time.Time) [][2]float64 {
+	n := int(to.Sub(from) / step)
+	if to.Sub(from)%step != 0 {
+		n++
+	}
+	hours := make([]float64, n)
+	for _, e := range p.Logs {
+		for _, iv := range e.intervals(now) {
+			start, end := iv[0], iv[1]
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			for start.Before(end) {
+				i := int(start.Sub(from) / step)
+				next := from.Add(time.Duration(i+1) * step)
+				if next.After(end) {
+					next = end
+				}
+				hours[i] += next.Sub(start).Hours()
+				start = next
+			}
+		}
+	}
+	points := make([][2]float64, n)
+	for i, h := range hours {
+		points[i] = [2]float64{h, float64(from.Add(time.Duration(i) * step).UnixMilli())}
+	}
+	return points
+}
+
+func grafanaSeriesFor(tracker *TrackerData, targets []string, from, to time.Time, step time.Duration, now time.Time) ([]grafanaSeries, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("empty range")
+	}
+	if step < time.Minute {
+		step = time.Minute
+	}
+	for to.Sub(from)/step > maxBuckets {
+		step *= 2
+	}
+	out := []grafanaSeries{}
+	for _, p := range tracker.Projects {
+		if len(targets) > 0 && !containsTarget(targets, p.Name) {
+			continue
+		}
+		out = append(out, grafanaSeries{Target: p.Name, Datapoints: bucketHours(p, from, to, step, now)})
+	}
+	return out, nil
+}
+
+func containsTarget(targets []string, name string) bool {
+	for _, t := range targets {
+		if t == "" || t == "*" || t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryTime accepts Unix milliseconds, as Grafana's ${__from}
+// expands to, or anything parseDateTime or parseDate does.
+func parseQueryTime(s string, now time.Time) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	if t, err := parseDateTime(s, now); err == nil {
+		return t, nil
+	}
+	return parseDate(s, now)
+}
+
+func (s *apiServer) registerGrafana(mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("POST /search", func(w http.ResponseWriter, r *http.Request) {
+		tracker, err := s.store.view()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		names := []string{}
+		for _, p := range tracker.Projects {
+			names = append(names, p.Name)
+		}
+		writeJSON(w, http.StatusOK, names)
+	})
+	mux.HandleFunc("POST /query", func(w http.ResponseWriter, r *http.Request) {
+		var q grafanaQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid query: " + err.Error()})
+			return
+		}
+		var targets []string
+		for _, t := range q.Targets {
+			targets = append(targets, t.Target)
+		}
+		s.writeSeries(w, targets, q.Range.From, q.Range.To, time.Duration(q.IntervalMs)*time.Millisecond)
+	})
+	mux.HandleFunc("GET /query", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC()
+		v := r.URL.Query()
+		from, err := parseQueryTime(v.Get("from"), now)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "from: " + err.Error()})
+			return
+		}
+		to := now
+		if v.Get("to") != "" {
+			if to, err = parseQueryTime(v.Get("to"), now); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "to: " + err.Error()})
+				return
+			}
+		}
+		step := 24 * time.Hour
+		if v.Get("interval") != "" {
+			if step, err = time.ParseDuration(v.Get("interval")); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "interval: " + err.Error()})
+				return
+			}
+		}
+		s.writeSeries(w, v["project"], from, to, step)
+	})
+}
+
+func (s *apiServer) writeSeries(w http.ResponseWriter, targets []string, from, to time.Time, step time.Duration) {
+	tracker, err := s.store.view()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	series, err := grafanaSeriesFor(tracker, targets, from.UTC(), to.UTC(), step, time.Now().UTC())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
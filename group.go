@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// firstWeekday is the configured "week_start" (default Monday).
+func firstWeekday() time.Weekday {
+	key := strings.ToLower(config.WeekStart)
+	if wd, ok := weekdayNames[key[:min(3, len(key))]]; ok {
+		return wd
+	}
+	return time.Monday
+}
+
+// weekStart returns the first day of the week containing day.
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) - int(firstWeekday()) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// isoWeekLabel names the week starting on start by its ISO week number,
+// taken from the week's Thursday when weeks start on Monday and from the
+// Monday inside it otherwise.
+func isoWeekLabel(start time.Time) string {
+	ref := start
+	for ref.Weekday() != time.Monday {
+		ref = ref.AddDate(0, 0, 1)
+	}
+	year, week := ref.ISOWeek()
+	return fmt.Sprintf("%d-W%02d (from %s)", year, week, start.Format("Jan 02"))
+}
+
+var groupings = []string{"day", "week", "month", "period", "quarter"}
+
+// bucketOf returns the start of the report bucket containing day. Periods
+// and quarters are fiscal, from fc.
+func bucketOf(day time.Time, by string, fc fiscalCalendar) time.Time {
+	switch by {
+	case "week":
+		return weekStart(day)
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "period":
+		_, _, start, _ := fc.period(day)
+		return start
+	case "quarter":
+		_, _, start, _ := fc.quarter(day)
+		return start
+	}
+	return day
+}
+
+func bucketLabel(start time.Time, by string, fc fiscalCalendar) string {
+	switch by {
+	case "week":
+		return isoWeekLabel(start)
+	case "month":
+		return start.Format("2006-01")
+	case "period":
+		year, n, _, end := fc.period(start)
+		return fmt.Sprintf("%s P%02d (%s to %s)", fc.yearLabel(year), n, start.Format("Jan 02"), end.AddDate(0, 0, -1).Format("Jan 02"))
+	case "quarter":
+		year, q, _, end := fc.quarter(start)
+		return fmt.Sprintf("%s Q%d (%s to %s)", fc.yearLabel(year), q, start.Format("Jan 02"), end.AddDate(0, 0, -1).Format("Jan 02"))
+	}
+	return start.Format("2006-01-02 Mon")
+}
+
+// reportGrouped prints per-project time for each day, week, month or
+// fiscal period or quarter in [from, to). It needs every entry, so
+// projects must be fully loaded.
+func reportGrouped(projects []Project, by string, from, to, now time.Time) {
+	fc, err := parseFiscal(config.Fiscal)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	type bucket struct {
+		start  time.Time
+		totals map[string]time.Duration
+	}
+	buckets := map[time.Time]*bucket{}
+	for _, p := range projects {
+		for key, d := range dayTotals(p, now) {
+			day, _ := time.Parse(dateLayout, key)
+			if !inRange(day, from, to) {
+				continue
+			}
+			start := bucketOf(day, by, fc)
+			b := buckets[start]
+			if b == nil {
+				b = &bucket{start: start, totals: map[string]time.Duration{}}
+				buckets[start] = b
+			}
+			b.totals[p.Name] += d
+		}
+	}
+	if len(buckets) == 0 {
+		fmt.Println("Nothing tracked in this range.")
+		return
+	}
+	starts := make([]time.Time, 0, len(buckets))
+	for s := range buckets {
+		starts = append(starts, s)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	fmt.Println("===================================================================")
+	fmt.Printf("Summary Report: By %s (%s)\n", by, rangeLabel(from, to))
+	fmt.Println("===================================================================")
+	var totalAll time.Duration
+	for _, s := range starts {
+		b := buckets[s]
+		fmt.Println(bucketLabel(s, by, fc))
+		var total time.Duration
+		for _, p := range projects {
+			if t := b.totals[p.Name]; t > 0 {
+				fmt.Printf("  %s | %10smin\n", projectLabel(p, 16), formatDecimal(t.Minutes(), 2))
+				total += t
+			}
+		}
+		fmt.Printf("  %-16s | %10smin\n", "Total", formatDecimal(total.Minutes(), 2))
+		totalAll += total
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("Total time tracked: %s minutes\n", formatDecimal(totalAll.Minutes(), 2))
+}
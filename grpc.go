@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serve also offers the v1 API as the gRPC service ptracker.v1.Tracker,
+// described in proto/ptracker/v1/tracker.proto, so clients can generate
+// typed stubs. gRPC runs over HTTP/2: with TLS as usual, otherwise as
+// cleartext HTTP/2 with prior knowledge. The messages are encoded here
+// rather than with the protobuf module, which keeps ptracker free of
+// dependencies; the field numbers in the .proto file are the contract.
+
+const grpcService = "ptracker.v1.Tracker"
+
+// grpcMaxMessage bounds a request message, as gRPC servers do by default.
+const grpcMaxMessage = 4 << 20
+
+// The gRPC status codes the service answers with.
+const (
+	grpcOK                 = 0
+	grpcInvalidArgument    = 3
+	grpcNotFound           = 5
+	grpcPermissionDenied   = 7
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+)
+
+type grpcError struct {
+	code int
+	msg  string
+}
+
+func (e grpcError) Error() string { return e.msg }
+
+var errBadMessage = grpcError{grpcInvalidArgument, "malformed request message"}
+
+// grpcCode maps an error to a gRPC status, the way errorStatus does for
+// HTTP.
+func grpcCode(err error) int {
+	var ge grpcError
+	if errors.As(err, &ge) {
+		return ge.code
+	}
+	switch errorStatus(err) {
+	case http.StatusNotFound:
+		return grpcNotFound
+	case http.StatusConflict:
+		return grpcFailedPrecondition
+	case http.StatusBadRequest:
+		return grpcInvalidArgument
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	}
+	return grpcInternal
+}
+
+func (s *apiServer) registerGRPC(mux *http.ServeMux) {
+	mux.HandleFunc("POST /"+grpcService+"/{method}", s.handleGRPC)
+}
+
+// handleGRPC answers one call. The status goes in the trailers, or in the
+// headers alone when the call fails before any message is sent.
+func (s *apiServer) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC needs HTTP/2 and Content-Type application/grpc", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	code, msg := grpcOK, ""
+	if err := s.grpcCall(w, r); err != nil {
+		code, msg = grpcCode(err), err.Error()
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", grpcEscape(msg))
+	}
+}
+
+func (s *apiServer) grpcCall(w http.ResponseWriter, r *http.Request) error {
+	msg, err := readGRPCMessage(r.Body)
+	if err != nil {
+		return err
+	}
+	switch method := r.PathValue("method"); method {
+	case "Start", "Stop":
+		req, err := decodeSessionRequest(msg)
+		if err != nil {
+			return err
+		}
+		if req.Project == "" {
+			return grpcError{grpcInvalidArgument, "project required"}
+		}
+		op := apiStart
+		if method == "Stop" {
+			op = apiStop
+		}
+		resp, err := s.runSession(r.URL.Path, req, op)
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, encodeSession(resp))
+	case "Report":
+		tracker, err := s.store.view()
+		if err != nil {
+			return err
+		}
+		return writeGRPCMessage(w, encodeReport(buildReport(tracker, time.Now().UTC())))
+	case "Status":
+		interval, err := decodeStatusRequest(msg)
+		if err != nil {
+			return err
+		}
+		return s.streamGRPCStatus(w, r, interval)
+	default:
+		return grpcError{grpcUnimplemented, fmt.Sprintf("unknown method %s/%s", grpcService, method)}
+	}
+}
+
+// streamGRPCStatus sends the active sessions every interval until the
+// client cancels, like /v1/status?stream=1.
+func (s *apiServer) streamGRPCStatus(w http.ResponseWriter, r *http.Request, interval time.Duration) error {
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tracker, err := s.store.view()
+		if err != nil {
+			return err
+		}
+		if err := writeGRPCMessage(w, encodeStatus(buildStatus(tracker, time.Now().UTC()))); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// readGRPCMessage reads the single length-prefixed message of a unary or
+// server-streaming call.
+func readGRPCMessage(body io.Reader) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(body, hdr[:]); err != nil {
+		return nil, grpcError{grpcInvalidArgument, "reading request: " + err.Error()}
+	}
+	if hdr[0] != 0 {
+		return nil, grpcError{grpcUnimplemented, "compressed messages are not supported"}
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > grpcMaxMessage {
+		return nil, grpcError{grpcResourceExhausted, fmt.Sprintf("request message of %d bytes is over %d", n, grpcMaxMessage)}
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return nil, grpcError{grpcInvalidArgument, "reading request: " + err.Error()}
+	}
+	return msg, nil
+}
+
+func writeGRPCMessage(w io.Writer, msg []byte) error {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+// grpcEscape percent-encodes a status message as the gRPC spec asks.
+func grpcEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pbEncoder appends protobuf wire format. As in proto3, fields holding
+// their zero value are left out.
+type pbEncoder []byte
+
+func (b *pbEncoder) key(field, wire int) {
+	*b = binary.AppendUvarint(*b, uint64(field<<3|wire))
+}
+
+func (b *pbEncoder) varint(field int, v uint64) {
+	if v != 0 {
+		b.key(field, 0)
+		*b = binary.AppendUvarint(*b, v)
+	}
+}
+
+func (b *pbEncoder) bool(field int, v bool) {
+	if v {
+		b.varint(field, 1)
+	}
+}
+
+func (b *pbEncoder) double(field int, f float64) {
+	if f != 0 {
+		b.key(field, 1)
+		*b = binary.LittleEndian.AppendUint64(*b, math.Float64bits(f))
+	}
+}
+
+// message always writes the field, so repeated entries keep their place
+// even when empty.
+func (b *pbEncoder) message(field int, m []byte) {
+	b.key(field, 2)
+	*b = binary.AppendUvarint(*b, uint64(len(m)))
+	*b = append(*b, m...)
+}
+
+func (b *pbEncoder) string(field int, s string) {
+	if s != "" {
+		b.message(field, []byte(s))
+	}
+}
+
+// timestamp writes a google.protobuf.Timestamp, or nothing for the zero
+// time.
+func (b *pbEncoder) timestamp(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	var ts pbEncoder
+	ts.varint(1, uint64(t.Unix()))
+	ts.varint(2, uint64(t.Nanosecond()))
+	b.message(field, ts)
+}
+
+// pbFields calls fn for each field of msg, with the value of a varint
+// field or the bytes of a length-delimited one. Fixed-width fields, which
+// no request uses, are skipped.
+func pbFields(msg []byte, fn func(field int, v uint64, p []byte)) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errBadMessage
+		}
+		msg = msg[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return errBadMessage
+			}
+			msg = msg[n:]
+			fn(field, v, nil)
+		case 1:
+			if len(msg) < 8 {
+				return errBadMessage
+			}
+			msg = msg[8:]
+		case 2:
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || l > uint64(len(msg)-n) {
+				return errBadMessage
+			}
+			fn(field, 0, msg[n:n+int(l)])
+			msg = msg[n+int(l):]
+		case 5:
+			if len(msg) < 4 {
+				return errBadMessage
+			}
+			msg = msg[4:]
+		default:
+			return errBadMessage
+		}
+	}
+	return nil
+}
+
+func decodeSessionRequest(msg []byte) (sessionRequest, error) {
+	var req sessionRequest
+	err := pbFields(msg, func(field int, v uint64, p []byte) {
+		switch field {
+		case 1:
+			req.Project = string(p)
+		case 2:
+			req.Note = string(p)
+		case 3:
+			req.For = string(p)
+		case 4:
+			req.Force = v != 0
+		}
+	})
+	return req, err
+}
+
+func decodeStatusRequest(msg []byte) (time.Duration, error) {
+	interval := 5 * time.Second
+	err := pbFields(msg, func(field int, v uint64, p []byte) {
+		if field == 1 && v > 0 && v <= math.MaxUint32 {
+			interval = time.Duration(v) * time.Second
+		}
+	})
+	return interval, err
+}
+
+func encodeSession(s sessionResponse) []byte {
+	var b pbEncoder
+	b.string(1, s.Project)
+	b.timestamp(2, s.Start)
+	b.timestamp(3, s.End)
+	b.double(4, s.DurationSeconds)
+	b.string(5, s.Note)
+	b.bool(6, s.Paused)
+	b.timestamp(7, s.Until)
+	return b
+}
+
+func encodeStatus(s statusResponse) []byte {
+	var b pbEncoder
+	b.timestamp(1, s.Time)
+	for _, a := range s.Active {
+		b.message(2, encodeSession(a))
+	}
+	return b
+}
+
+func encodeReport(r reportResponse) []byte {
+	var b pbEncoder
+	for _, row := range r.Projects {
+		var rb pbEncoder
+		rb.string(1, row.Project)
+		rb.varint(2, uint64(row.Sessions))
+		rb.double(3, row.TotalSeconds)
+		rb.double(4, row.Percent)
+		b.message(1, rb)
+	}
+	b.double(2, r.TotalSeconds)
+	return b
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// grpcTestServer serves the gRPC API over cleartext HTTP/2, the way serve
+// does without TLS, and returns a client that speaks it.
+func grpcTestServer(t *testing.T) (*httptest.Server, *http.Client) {
+	t.Helper()
+	api := &apiServer{store: newStore(filepath.Join(t.TempDir(), jsonDataFile))}
+	if err := api.store.update("create", func(tracker *TrackerData) error {
+		tracker.Projects = append(tracker.Projects, Project{Name: "acme"})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	api.registerGRPC(mux)
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	var p http.Protocols
+	p.SetUnencryptedHTTP2(true)
+	return srv, &http.Client{Transport: &http.Transport{Protocols: &p}}
+}
+
+func grpcRequest(ctx context.Context, t *testing.T, srv *httptest.Server, method string, msg []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writeGRPCMessage(&body, msg)
+	req, err := http.NewRequestWithContext(ctx, "POST", srv.URL+"/"+grpcService+"/"+method, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	return req
+}
+
+// readFrame reads one response message.
+func readFrame(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// grpcUnary makes a call and returns its message, if any, and status.
+func grpcUnary(t *testing.T, srv *httptest.Server, client *http.Client, method string, msg []byte) ([]byte, string) {
+	t.Helper()
+	resp, err := client.Do(grpcRequest(context.Background(), t, srv, method, msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+	}
+	if len(body) == 0 {
+		return nil, status
+	}
+	return readFrame(t, bytes.NewReader(body)), status
+}
+
+func pbString(t *testing.T, msg []byte, field int) string {
+	t.Helper()
+	var s string
+	if err := pbFields(msg, func(f int, _ uint64, p []byte) {
+		if f == field {
+			s = string(p)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGRPCStartStatusStop(t *testing.T) {
+	srv, client := grpcTestServer(t)
+	var req pbEncoder
+	req.string(1, "acme")
+	req.string(2, "from a plugin")
+
+	msg, status := grpcUnary(t, srv, client, "Start", req)
+	if status != "0" {
+		t.Fatalf("Start status %s", status)
+	}
+	if got := pbString(t, msg, 1); got != "acme" {
+		t.Errorf("Start returned project %q", got)
+	}
+	if got := pbString(t, msg, 5); got != "from a plugin" {
+		t.Errorf("Start returned note %q", got)
+	}
+
+	if _, status := grpcUnary(t, srv, client, "Start", req); status != "9" {
+		t.Errorf("second Start status %s, want 9 (failed precondition)", status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := client.Do(grpcRequest(ctx, t, srv, "Status", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var active []string
+	if err := pbFields(readFrame(t, resp.Body), func(f int, _ uint64, p []byte) {
+		if f == 2 {
+			active = append(active, pbString(t, p, 1))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	resp.Body.Close()
+	if len(active) != 1 || active[0] != "acme" {
+		t.Errorf("Status lists %q, want [acme]", active)
+	}
+
+	if _, status := grpcUnary(t, srv, client, "Stop", req); status != "0" {
+		t.Errorf("Stop status %s", status)
+	}
+	var missing pbEncoder
+	missing.string(1, "nope")
+	if _, status := grpcUnary(t, srv, client, "Stop", missing); status != "5" {
+		t.Errorf("Stop of an unknown project status %s, want 5 (not found)", status)
+	}
+	if _, status := grpcUnary(t, srv, client, "Pause", req); status != "12" {
+		t.Errorf("unknown method status %s, want 12 (unimplemented)", status)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultHeartbeatIdle = 5 * time.Minute
+	sourceHeartbeat      = "heartbeat"
+)
+
+type heartbeatRequest struct {
+	Project string    `json:"project"`
+	Time    time.Time `json:"time,omitzero"`
+}
+
+func heartbeatIdle() time.Duration {
+	if d, err := time.ParseDuration(config.HeartbeatIdle); err == nil && d > 0 {
+		return d
+	}
+	return defaultHeartbeatIdle
+}
+
+// applyHeartbeat coalesces a heartbeat into the project's log: it extends
+// a heartbeat entry that ended within the idle gap before t, otherwise it
+// opens a new zero-length one. A running manual session absorbs it. It
+// returns the index of the affected entry.
+func applyHeartbeat(tracker *TrackerData, name string, t time.Time, idle time.Duration) (*Project, int, error) {
+	p := findProject(tracker, name)
+	if p == nil {
+		return nil, 0, notFoundError(name)
+	}
+	if n := len(p.Logs); n > 0 && p.Logs[n-1].End.IsZero() && !t.Before(p.Logs[n-1].Start) {
+		return p, n - 1, nil
+	}
+	for i := range p.Logs {
+		e := &p.Logs[i]
+		if e.Source != sourceHeartbeat || t.Before(e.Start) {
+			continue
+		}
+		if !t.After(e.End) {
+			return p, i, nil
+		}
+		if t.Sub(e.End) <= idle {
+			p.TotalTime += t.Sub(e.End)
+			e.End = t
+			return p, i, nil
+		}
+	}
+	i := insertEntry(p, LogEntry{Start: t, End: t, User: currentUser(), Source: sourceHeartbeat})
+	return p, i, nil
+}
+
+func (s *apiServer) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req heartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Project == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "project required"})
+		return
+	}
+	now := time.Now().UTC()
+	t := req.Time.UTC()
+	if req.Time.IsZero() {
+		t = now
+	}
+	var resp sessionResponse
+	err := s.store.update("heartbeat "+req.Project, func(tracker *TrackerData) error {
+		p, i, err := applyHeartbeat(tracker, req.Project, t, heartbeatIdle())
+		if err != nil {
+			return err
+		}
+		resp = newSessionResponse(p.Name, p.Logs[i], now)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+}
+
+// haEntity is a Home Assistant MQTT discovery config.
+type haEntity struct {
+	Name              string   `json:"name"`
+	UniqueID          string   `json:"unique_id"`
+	StateTopic        string   `json:"state_topic"`
+	ValueTemplate     string   `json:"value_template"`
+	Unit              string   `json:"unit_of_measurement,omitempty"`
+	DeviceClass       string   `json:"device_class,omitempty"`
+	StateClass        string   `json:"state_class,omitempty"`
+	Icon              string   `json:"icon,omitempty"`
+	PayloadOn         string   `json:"payload_on,omitempty"`
+	PayloadOff        string   `json:"payload_off,omitempty"`
+	Device            haDevice `json:"device"`
+	component, object string
+}
+
+type haState struct {
+	Project    string  `json:"project"`
+	Tracking   string  `json:"tracking"`
+	TodayHours float64 `json:"today_hours"`
+}
+
+// todayTotal is the time tracked today across all projects except breaks.
+func todayTotal(tracker *TrackerData, now time.Time) time.Duration {
+	today, _ := parseDate("today", now)
+	days := map[string]time.Duration{}
+	for _, p := range tracker.Projects {
+		if p.Name == breakProject {
+			continue
+		}
+		for _, e := range p.Logs {
+			if e.End.IsZero() || e.End.After(today) {
+				addByDay(days, e, now)
+			}
+		}
+	}
+	return days[dayKey(today)]
+}
+
+// haMessages returns the retained discovery configs for the ptracker
+// sensors and their current state, so they appear in Home Assistant
+// without any YAML.
+func haMessages(tracker *TrackerData, now time.Time) []mqttMessage {
+	prefix := config.MQTT.DiscoveryPrefix
+	if prefix == "" {
+		prefix = "homeassistant"
+	}
+	stateTopic := config.MQTT.topic() + "/ha"
+	device := haDevice{Identifiers: []string{"ptracker"}, Name: "ptracker", Manufacturer: "ptracker"}
+	entities := []haEntity{
+		{Name: "Active project", component: "sensor", object: "active_project",
+			ValueTemplate: "{{ value_json.project }}", Icon: "mdi:briefcase-clock"},
+		{Name: "Tracked today", component: "sensor", object: "today",
+			ValueTemplate: "{{ value_json.today_hours }}", Unit: "h", DeviceClass: "duration", StateClass: "measurement"},
+		{Name: "Tracking", component: "binary_sensor", object: "tracking",
+			ValueTemplate: "{{ value_json.tracking }}", PayloadOn: "ON", PayloadOff: "OFF", Icon: "mdi:timer"},
+	}
+	var msgs []mqttMessage
+	for _, e := range entities {
+		e.UniqueID = "ptracker_" + e.object
+		e.StateTopic = stateTopic
+		e.Device = device
+		data, _ := json.Marshal(e)
+		msgs = append(msgs, mqttMessage{topic: prefix + "/" + e.component + "/ptracker/" + e.object + "/config", payload: data, retain: true})
+	}
+	var active []string
+	for _, p := range tracker.Projects {
+		if p.running() != nil {
+			active = append(active, p.Name)
+		}
+	}
+	state := haState{Project: "idle", Tracking: "OFF"}
+	if len(active) > 0 {
+		state.Project, state.Tracking = strings.Join(active, ", "), "ON"
+	}
+	state.TodayHours = math.Round(todayTotal(tracker, now).Hours()*100) / 100
+	data, _ := json.Marshal(state)
+	return append(msgs, mqttMessage{topic: stateTopic, payload: data, retain: true})
+}
+
+// runHomeAssistant republishes the sensors every minute so today's total
+// keeps up with running sessions.
+func (s *apiServer) runHomeAssistant() {
+	for range time.Tick(time.Minute) {
+		tracker, err := s.store.view()
+		if err != nil {
+			log.Println("homeassistant:", err)
+			continue
+		}
+		if err := mqttPublish(config.MQTT, haMessages(tracker, time.Now().UTC())); err != nil {
+			log.Println("homeassistant:", err)
+		}
+	}
+}
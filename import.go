@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// entryHash identifies an entry by content so re-running an import is
+// idempotent. Times are truncated to seconds because most exchange
+// formats don't carry more.
+func entryHash(project string, e LogEntry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d", project, e.Start.Unix(), e.End.Unix())
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+type importedEntry struct {
+	project string
+	entry   LogEntry
+}
+
+// csvColumns locates named columns in a header row, case-insensitively.
+func csvColumns(header []string, names ...string) (map[string]int, error) {
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	out := map[string]int{}
+	for _, n := range names {
+		i, ok := cols[n]
+		if !ok {
+			return nil, fmt.Errorf("missing column %q", n)
+		}
+		out[n] = i
+	}
+	return out, nil
+}
+
+// readCSVEntries reads ptracker's own CSV layout: project,start,end and
+// optional note and user columns.
+func readCSVEntries(r io.Reader, now time.Time) ([]importedEntry, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	cols, err := csvColumns(rows[0], "project", "start", "end")
+	if err != nil {
+		return nil, err
+	}
+	noteCol, userCol := -1, -1
+	if c, err := csvColumns(rows[0], "note"); err == nil {
+		noteCol = c["note"]
+	}
+	if c, err := csvColumns(rows[0], "user"); err == nil {
+		userCol = c["user"]
+	}
+	var out []importedEntry
+	for i, row := range rows[1:] {
+		start, err := parseDateTime(row[cols["start"]], now)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		end, err := parseDateTime(row[cols["end"]], now)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		e := LogEntry{Start: start, End: end}
+		if noteCol >= 0 && noteCol < len(row) {
+			e.Note = row[noteCol]
+		}
+		if userCol >= 0 && userCol < len(row) {
+			e.User = row[userCol]
+		}
+		out = append(out, importedEntry{row[cols["project"]], e})
+	}
+	return out, nil
+}
+
+// readTogglEntries reads a Toggl Track "detailed report" CSV export.
+func readTogglEntries(r io.Reader) ([]importedEntry, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	cols, err := csvColumns(rows[0], "project", "description", "start date", "start time", "end date", "end time")
+	if err != nil {
+		return nil, err
+	}
+	userCol := -1
+	if c, err := csvColumns(rows[0], "user"); err == nil {
+		userCol = c["user"]
+	}
+	var out []importedEntry
+	for i, row := range rows[1:] {
+		start, err := time.Parse("2006-01-02 15:04:05", row[cols["start date"]]+" "+row[cols["start time"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		end, err := time.Parse("2006-01-02 15:04:05", row[cols["end date"]]+" "+row[cols["end time"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		e := LogEntry{Start: start, End: end, Note: row[cols["description"]]}
+		if userCol >= 0 {
+			e.User = row[userCol]
+		}
+		project := row[cols["project"]]
+		if project == "" {
+			project = "toggl"
+		}
+		out = append(out, importedEntry{project, e})
+	}
+	return out, nil
+}
+
+// importEntries adds entries to tracker, creating projects as needed and
+// skipping any whose content hash is already present. Entries starting in
+// a locked period are skipped too unless force is set.
+func importEntries(tracker *TrackerData, entries []importedEntry, force bool) (added, skipped, locked int, created []string) {
+	seen := map[string]bool{}
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			seen[entryHash(p.Name, e)] = true
+		}
+	}
+	touched := map[string]bool{}
+	for _, ie := range entries {
+		h := entryHash(ie.project, ie.entry)
+		if seen[h] {
+			skipped++
+			continue
+		}
+		if !force && lockedAt(tracker, ie.project, ie.entry) != nil {
+			locked++
+			continue
+		}
+		seen[h] = true
+		p := findProject(tracker, ie.project)
+		if p == nil {
+			tracker.Projects = append(tracker.Projects, Project{Name: ie.project})
+			p = &tracker.Projects[len(tracker.Projects)-1]
+			created = append(created, ie.project)
+		}
+		if ie.entry.User == "" {
+			ie.entry.User = currentUser()
+		}
+		insertEntry(p, ie.entry)
+		touched[p.Name] = true
+		added++
+	}
+	for name := range touched {
+		recomputeTotal(findProject(tracker, name))
+	}
+	return added, skipped, locked, created
+}
+
+func cmdImport(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	project := fs.String("project", "", "import every entry into this project")
+	replace := fs.Bool("replace", false, "json: replace all data with the file")
+	merge := fs.Bool("merge", false, "json: merge the file into the data")
+	yes := fs.Bool("yes", false, "json: replace without asking")
+	force := fs.Bool("force", false, "import entries inside locked periods")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 2 {
+		fmt.Println("Format and file required: import [csv|toggl|json] FILE\n", helpText)
+		return
+	}
+	f, err := openMaybeGzip(pos[1])
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer f.Close()
+	var entries []importedEntry
+	switch pos[0] {
+	case "csv":
+		entries, err = readCSVEntries(f, now)
+	case "toggl":
+		entries, err = readTogglEntries(f)
+	case "json":
+		if *replace == *merge {
+			fmt.Println("Use one of --replace or --merge.")
+			return
+		}
+		in, err := readJSONExport(f, pos[1])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		importJSON(dataPath, tracker, in, *replace, *yes, *force, now)
+		return
+	default:
+		fmt.Printf("Unknown import format '%s'. Use csv, toggl or json.\n", pos[0])
+		return
+	}
+	if err != nil {
+		fmt.Println("Error reading", pos[1]+":", err)
+		return
+	}
+	for i := range entries {
+		if *project != "" {
+			entries[i].project = *project
+		}
+		if entries[i].entry.End.Before(entries[i].entry.Start) {
+			fmt.Printf("Entry %d ends before it starts.\n", i+1)
+			return
+		}
+	}
+	added, skipped, locked, created := importEntries(tracker, entries, *force)
+	if added > 0 {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	for _, name := range created {
+		fmt.Printf("Project '%s' created.\n", name)
+	}
+	fmt.Printf("Imported %d entries, skipped %d already present.\n", added, skipped)
+	if locked > 0 {
+		fmt.Printf("Skipped %d entries in locked periods (--force to import them).\n", locked)
+	}
+}
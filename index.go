@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dailyIndex caches closed-entry totals per project per UTC day so
+// date-bounded reports don't have to walk every entry. It is updated on
+// save, appending only entries added since the last update when the
+// already-indexed prefix of a project's log is unchanged.
+type dailyIndex struct {
+	// Checksum is the data file checksum the index was built against.
+	Checksum string                   `json:"checksum"`
+	Projects map[string]*projectIndex `json:"projects"`
+}
+
+type projectIndex struct {
+	Entries     int                      `json:"entries"`
+	Fingerprint uint64                   `json:"fingerprint"`
+	Days        map[string]time.Duration `json:"days"`
+	// Sessions counts entries by the day they started.
+	Sessions map[string]int `json:"sessions"`
+}
+
+func indexPath(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), "index.json")
+}
+
+func loadIndex(dataPath string) *dailyIndex {
+	idx := &dailyIndex{}
+	if data, err := os.ReadFile(indexPath(dataPath)); err == nil {
+		json.Unmarshal(data, idx)
+	}
+	if idx.Projects == nil {
+		idx.Projects = map[string]*projectIndex{}
+	}
+	return idx
+}
+
+func saveIndex(dataPath string, idx *dailyIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(indexPath(dataPath), data, 0644)
+}
+
+// intervals returns the worked spans of the entry, i.e. [Start, End)
+// minus pauses. Open entries and pauses run until now.
+func (e LogEntry) intervals(now time.Time) [][2]time.Time {
+	end := e.End
+	if end.IsZero() {
+		end = now
+	}
+	var out [][2]time.Time
+	cur := e.Start
+	for _, p := range e.Pauses {
+		pend := p.End
+		if pend.IsZero() {
+			pend = end
+		}
+		if p.Start.After(cur) {
+			out = append(out, [2]time.Time{cur, p.Start})
+		}
+		if pend.After(cur) {
+			cur = pend
+		}
+	}
+	if end.After(cur) {
+		out = append(out, [2]time.Time{cur, end})
+	}
+	return out
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// addByDay adds the entry's worked time to days, split at UTC midnight.
+func addByDay(days map[string]time.Duration, e LogEntry, now time.Time) {
+	for _, iv := range e.intervals(now) {
+		start, end := iv[0].UTC(), iv[1].UTC()
+		for start.Before(end) {
+			midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+			stop := end
+			if midnight.Before(end) {
+				stop = midnight
+			}
+			days[dayKey(start)] += stop.Sub(start)
+			start = stop
+		}
+	}
+}
+
+func fingerprintEntries(logs []LogEntry) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(t time.Time) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(t.UnixNano()))
+		h.Write(buf[:])
+	}
+	for _, e := range logs {
+		put(e.Start)
+		put(e.End)
+		for _, p := range e.Pauses {
+			put(p.Start)
+			put(p.End)
+		}
+	}
+	return h.Sum64()
+}
+
+// updateIndex brings idx up to date with tracker. Only closed entries are
+// indexed, up to the first open one.
+func updateIndex(idx *dailyIndex, tracker *TrackerData) {
+	seen := map[string]bool{}
+	for _, p := range tracker.Projects {
+		seen[p.Name] = true
+		closed := len(p.Logs)
+		for i, e := range p.Logs {
+			if e.End.IsZero() {
+				closed = i
+				break
+			}
+		}
+		pi := idx.Projects[p.Name]
+		if pi == nil || pi.Entries > closed || fingerprintEntries(p.Logs[:pi.Entries]) != pi.Fingerprint {
+			pi = &projectIndex{Days: map[string]time.Duration{}, Sessions: map[string]int{}}
+			idx.Projects[p.Name] = pi
+		}
+		for _, e := range p.Logs[pi.Entries:closed] {
+			addByDay(pi.Days, e, e.End)
+			pi.Sessions[dayKey(e.Start)]++
+		}
+		pi.Entries = closed
+		pi.Fingerprint = fingerprintEntries(p.Logs[:closed])
+	}
+	for name := range idx.Projects {
+		if !seen[name] {
+			delete(idx.Projects, name)
+		}
+	}
+	idx.Checksum = tracker.Checksum
+}
+
+// indexFor returns an index matching the data file, rebuilding it from a
+// full load when it is missing or stale (e.g. after an external edit).
+func indexFor(dataPath string, checksum string) (*dailyIndex, error) {
+	idx := loadIndex(dataPath)
+	if idx.Checksum != "" && idx.Checksum == checksum {
+		return idx, nil
+	}
+	tracker, err := loadTracker(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	updateIndex(idx, tracker)
+	if !readOnly {
+		saveIndex(dataPath, idx)
+	}
+	return idx, nil
+}
+
+// rangeTotal sums the project's indexed days in [from, to) plus the part
+// of a running session inside the range, and counts sessions started in
+// it. Zero bounds are open-ended.
+func rangeTotal(idx *dailyIndex, p Project, from, to, now time.Time) (total time.Duration, sessions int) {
+	if pi := idx.Projects[p.Name]; pi != nil {
+		for day, d := range pi.Days {
+			t, _ := time.Parse(dateLayout, day)
+			if inRange(t, from, to) {
+				total += d
+			}
+		}
+		for day, n := range pi.Sessions {
+			t, _ := time.Parse(dateLayout, day)
+			if inRange(t, from, to) {
+				sessions += n
+			}
+		}
+	}
+	if e := p.running(); e != nil {
+		days := map[string]time.Duration{}
+		addByDay(days, *e, now)
+		for day, d := range days {
+			t, _ := time.Parse(dateLayout, day)
+			if inRange(t, from, to) {
+				total += d
+			}
+		}
+		if inRange(e.Start, from, to) {
+			sessions++
+		}
+	}
+	return total, sessions
+}
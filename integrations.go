@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Integration settings can be set per project in its metadata (set
+// --meta KEY=VALUE), overriding the global setting from config, so each
+// client's time can go to its own systems.
+const (
+	metaWebhook        = "webhook"
+	metaNotionDatabase = "notion.database"
+)
+
+// projectSetting returns the project's metadata value for key, or
+// fallback when the project doesn't set it.
+func projectSetting(p *Project, key, fallback string) string {
+	if p != nil && p.Meta[key] != "" {
+		return p.Meta[key]
+	}
+	return fallback
+}
+
+// postWebhook sends a session event as JSON.
+func postWebhook(url string, ev sessionEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
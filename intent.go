@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type intentRequest struct {
+	// Action is start, stop, toggle, status or today.
+	Action string `json:"action"`
+	// Project is matched loosely, as a speech recognizer might spell it.
+	Project string `json:"project,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+type intentResponse struct {
+	// Speech is a sentence confirming what was done, or why not.
+	Speech  string `json:"speech"`
+	Project string `json:"project,omitempty"`
+}
+
+// spokenName reduces a name to lower-case letters and digits, so "The
+// Thesis!" and "thesis" compare equal.
+func spokenName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, article := range []string{"the ", "my ", "a "} {
+		s = strings.TrimPrefix(s, article)
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ra {
+		cur := []int{i + 1}
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			cur = append(cur, min(prev[j]+cost, prev[j+1]+1, cur[j]+1))
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// resolveProject finds the project a spoken name refers to: an exact
+// match, else the only project it is a prefix or part of, else the only
+// closest name within a few typos. Closed projects are only matched
+// exactly.
+func resolveProject(tracker *TrackerData, spoken string) (*Project, error) {
+	if p := findProject(tracker, spoken); p != nil {
+		return p, nil
+	}
+	want := spokenName(spoken)
+	if want == "" {
+		return nil, notFoundError(spoken)
+	}
+	var candidates []*Project
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		if p.Name != breakProject && !p.closed() {
+			candidates = append(candidates, p)
+		}
+	}
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == want },
+		func(name string) bool { return strings.HasPrefix(name, want) },
+		func(name string) bool {
+			return strings.Contains(name, want) || len(name) >= 3 && strings.Contains(want, name)
+		},
+	}
+	for _, match := range matchers {
+		var found []*Project
+		for _, p := range candidates {
+			if match(spokenName(p.Name)) {
+				found = append(found, p)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], nil
+		}
+		if len(found) > 1 {
+			return nil, ambiguousError{spoken, found}
+		}
+	}
+	best, bestDist := []*Project(nil), len([]rune(want))/3+1
+	for _, p := range candidates {
+		switch d := editDistance(want, spokenName(p.Name)); {
+		case d < bestDist:
+			best, bestDist = []*Project{p}, d
+		case d == bestDist && best != nil:
+			best = append(best, p)
+		}
+	}
+	if len(best) == 1 {
+		return best[0], nil
+	}
+	if len(best) > 1 {
+		return nil, ambiguousError{spoken, best}
+	}
+	return nil, notFoundError(spoken)
+}
+
+type ambiguousError struct {
+	spoken string
+	found  []*Project
+}
+
+func (e ambiguousError) Error() string {
+	names := make([]string, len(e.found))
+	for i, p := range e.found {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("'%s' could be %s.", e.spoken, strings.Join(names, " or "))
+}
+
+// spokenDuration says a duration the way a person would, e.g. "1 hour
+// and 5 minutes".
+func spokenDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case h == 0:
+		return plural(m, "minute")
+	case m == 0:
+		return plural(h, "hour")
+	}
+	return plural(h, "hour") + " and " + plural(m, "minute")
+}
+
+// handleIntent serves POST /intent for voice assistants. Failures are
+// answered with a sentence as well, along with the matching status code.
+func (s *apiServer) handleIntent(w http.ResponseWriter, r *http.Request) {
+	var req intentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, intentResponse{Speech: "I didn't understand that request."})
+		return
+	}
+	now := time.Now().UTC()
+	var resp intentResponse
+	var err error
+	switch action := strings.ToLower(req.Action); action {
+	case "start", "stop", "toggle":
+		if action != "stop" && req.Project == "" {
+			writeJSON(w, http.StatusBadRequest, intentResponse{Speech: "Which project?"})
+			return
+		}
+		err = s.store.update("intent "+action+" "+req.Project, func(tracker *TrackerData) error {
+			var name string
+			if req.Project != "" {
+				p, err := resolveProject(tracker, req.Project)
+				if err != nil {
+					return err
+				}
+				name = p.Name
+			}
+			resp, err = intentSession(tracker, action, name, req.Note, now)
+			return err
+		})
+	case "status", "today":
+		var tracker *TrackerData
+		if tracker, err = s.store.view(); err == nil {
+			resp = intentReport(tracker, action, now)
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, intentResponse{Speech: "I can start, stop or toggle a project, or tell you the status or today's time."})
+		return
+	}
+	if err != nil {
+		writeJSON(w, errorStatus(err), intentResponse{Speech: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// intentSession starts, stops or toggles a session and says what it did.
+// Stop without a project stops whatever is running.
+func intentSession(tracker *TrackerData, action, name, note string, now time.Time) (intentResponse, error) {
+	if action == "toggle" {
+		p, started, dur, err := toggleSession(tracker, name, note, false, now)
+		if err != nil || started {
+			return intentResponse{Speech: "Started " + name + ".", Project: name}, err
+		}
+		return intentResponse{Speech: fmt.Sprintf("Stopped %s after %s.", p.Name, spokenDuration(dur)), Project: p.Name}, nil
+	}
+	if action == "start" {
+		_, err := startSession(tracker, name, note, false, now)
+		return intentResponse{Speech: "Started " + name + ".", Project: name}, err
+	}
+	names, durs, err := stopActive(tracker, name, now)
+	if err != nil {
+		return intentResponse{}, err
+	}
+	var parts []string
+	for i, n := range names {
+		parts = append(parts, fmt.Sprintf("%s after %s", n, spokenDuration(durs[i])))
+	}
+	return intentResponse{Speech: "Stopped " + strings.Join(parts, " and ") + ".", Project: names[0]}, nil
+}
+
+// intentReport answers "status" and "today" in a sentence.
+func intentReport(tracker *TrackerData, action string, now time.Time) intentResponse {
+	if action == "today" {
+		return intentResponse{Speech: "You've tracked " + spokenDuration(todayTotal(tracker, now)) + " today."}
+	}
+	var parts []string
+	var first string
+	for _, p := range tracker.Projects {
+		if e := p.running(); e != nil {
+			parts = append(parts, fmt.Sprintf("%s for %s", p.Name, spokenDuration(e.Duration(now))))
+			if first == "" {
+				first = p.Name
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return intentResponse{Speech: "Nothing is being tracked."}
+	}
+	return intentResponse{Speech: "Tracking " + strings.Join(parts, " and ") + ".", Project: first}
+}
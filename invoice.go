@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Invoice is a ledger record of an issued invoice. Entries it covers
+// carry its number in LogEntry.Invoice and are left out of later ones.
+type Invoice struct {
+	Number  string        `json:"number"`
+	Project string        `json:"project"`
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	Issued  time.Time     `json:"issued"`
+	Time    time.Duration `json:"time"`
+	Amount  float64       `json:"amount"`
+	// Expenses is the part of Amount from billed expenses.
+	Expenses float64   `json:"expenses,omitempty"`
+	Paid     time.Time `json:"paid,omitzero"`
+}
+
+func (inv Invoice) status() string {
+	if inv.Paid.IsZero() {
+		return "unpaid"
+	}
+	return "paid"
+}
+
+func findInvoice(tracker *TrackerData, number string) *Invoice {
+	for i := range tracker.Invoices {
+		if tracker.Invoices[i].Number == number {
+			return &tracker.Invoices[i]
+		}
+	}
+	return nil
+}
+
+// nextInvoiceNumber numbers invoices per year: 2024-001, 2024-002, ...
+func nextInvoiceNumber(tracker *TrackerData, now time.Time) string {
+	prefix := now.Format("2006") + "-"
+	n := 1
+	for findInvoice(tracker, fmt.Sprintf("%s%03d", prefix, n)) != nil {
+		n++
+	}
+	return fmt.Sprintf("%s%03d", prefix, n)
+}
+
+func cmdInvoice(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("invoice", flag.ContinueOnError)
+	fromStr := fs.String("from", "", "first day to bill")
+	toStr := fs.String("to", "", "last day to bill")
+	number := fs.String("number", "", "invoice number (default YEAR-NNN)")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 2 || pos[0] != "create" {
+		fmt.Println("Usage: invoice create [project] [--from DATE] [--to DATE] [--number N]")
+		return
+	}
+	p := lookupProject(tracker, pos[1])
+	if p == nil {
+		fmt.Println(notFoundError(pos[1]))
+		return
+	}
+	if p.Rate <= 0 {
+		fmt.Printf("'%s' has no rate. Use 'set %s --rate N'.\n", p.Name, p.Name)
+		return
+	}
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *number == "" {
+		*number = nextInvoiceNumber(tracker, now)
+	} else if findInvoice(tracker, *number) != nil {
+		fmt.Printf("Invoice '%s' exists.\n", *number)
+		return
+	}
+	round, err := billingRounder(config.Rounding)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	inv := Invoice{Number: *number, Project: p.Name, From: from, To: to, Issued: now}
+	var lines []string
+	for i := range p.Logs {
+		e := &p.Logs[i]
+		if e.End.IsZero() || e.Invoice != "" || !e.isBillable() || !inRange(e.Start, from, to) {
+			continue
+		}
+		d := round(e.Duration(e.End))
+		e.Invoice = inv.Number
+		inv.Time += d
+		if inv.From.IsZero() || e.Start.Before(inv.From) {
+			inv.From = e.Start
+		}
+		lines = append(lines, fmt.Sprintf("  %s  %6sh  %10s  %s", formatDate(e.Start), formatDecimal(d.Hours(), 2), formatMoney(earnings(d, p.Rate)), e.Note))
+	}
+	var costs []string
+	for i := range p.Expenses {
+		x := &p.Expenses[i]
+		if x.Invoice != "" || !inRange(x.Date, from, to) {
+			continue
+		}
+		x.Invoice = inv.Number
+		inv.Expenses += x.Amount
+		costs = append(costs, fmt.Sprintf("  %s  %18s  %s", x.Date.Format(dateLayout), formatMoney(x.Amount), x.Note))
+	}
+	if len(lines) == 0 && len(costs) == 0 {
+		fmt.Println("No uninvoiced entries in range.")
+		return
+	}
+	if inv.To.IsZero() {
+		today, _ := parseDate("today", now)
+		inv.To = today.AddDate(0, 0, 1)
+	}
+	inv.Amount = earnings(inv.Time, p.Rate) + inv.Expenses
+	tracker.Invoices = append(tracker.Invoices, inv)
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Invoice %s - %s\n", inv.Number, p.Name)
+	fmt.Printf("Issued %s, period %s\n", formatDate(inv.Issued), rangeLabel(inv.From, inv.To))
+	fmt.Println(strings.Repeat("-", 50))
+	if len(lines) > 0 {
+		fmt.Println(strings.Join(lines, "\n"))
+		fmt.Println(strings.Repeat("-", 50))
+		fmt.Printf("  %6sh at %s/h: %s\n", formatDecimal(inv.Time.Hours(), 2), formatMoney(p.Rate), formatMoney(earnings(inv.Time, p.Rate)))
+	}
+	if len(costs) > 0 {
+		fmt.Println("Expenses:")
+		fmt.Println(strings.Join(costs, "\n"))
+		fmt.Println(strings.Repeat("-", 50))
+		fmt.Printf("  Expenses: %s\n", formatMoney(inv.Expenses))
+	}
+	fmt.Printf("  Total: %s\n", formatMoney(inv.Amount))
+}
+
+func cmdInvoices(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	if len(args) < 1 || args[0] == "list" {
+		if len(tracker.Invoices) == 0 {
+			fmt.Println("No invoices.")
+			return
+		}
+		var unpaid float64
+		fmt.Printf("%-10s | %-16s | %-24s | %10s | %s\n", "Number", "Project", "Period", "Amount", "Status")
+		for _, inv := range tracker.Invoices {
+			fmt.Printf("%-10s | %-16s | %-24s | %10s | %s\n", inv.Number, inv.Project, rangeLabel(inv.From, inv.To), formatMoney(inv.Amount), inv.status())
+			if inv.Paid.IsZero() {
+				unpaid += inv.Amount
+			}
+		}
+		fmt.Printf("Outstanding: %s\n", formatMoney(unpaid))
+		return
+	}
+	if args[0] != "mark-paid" || len(args) < 2 {
+		fmt.Println("Usage: invoices [list|mark-paid NUMBER]")
+		return
+	}
+	inv := findInvoice(tracker, args[1])
+	if inv == nil {
+		fmt.Printf("Invoice '%s' not found.\n", args[1])
+		return
+	}
+	if !inv.Paid.IsZero() {
+		fmt.Printf("Invoice %s was already paid on %s.\n", inv.Number, formatDate(inv.Paid))
+		return
+	}
+	inv.Paid = now
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Invoice %s marked paid.\n", inv.Number)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ipcCommands ask a running serve for the summary over its socket rather
+// than reading the data file, which keeps polling them every second cheap.
+var ipcCommands = map[string]bool{
+	"status":  true,
+	"today":   true,
+	"prompt":  true,
+	"current": true,
+	"elapsed": true,
+}
+
+// ipcPath is the Unix socket serve listens on beside the data file.
+func ipcPath(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), "serve.sock")
+}
+
+// serveIPC answers GET /summary on the socket with the summary of the
+// store's current snapshot. Only the owner may connect, so it needs no
+// token.
+func (s *apiServer) serveIPC() {
+	path := ipcPath(s.store.dataPath)
+	if c, err := net.DialTimeout("unix", path, 100*time.Millisecond); err == nil {
+		c.Close()
+		log.Println("ipc: another serve is listening on", path)
+		return
+	}
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		log.Println("ipc:", err)
+		return
+	}
+	os.Chmod(path, 0600)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /summary", func(w http.ResponseWriter, r *http.Request) {
+		tracker, err := s.store.view()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newSummaryCache(tracker))
+	})
+	log.Println("ipc: listening on", path)
+	if err := http.Serve(l, mux); err != nil {
+		log.Println("ipc:", err)
+	}
+}
+
+// loadViaDaemon gets the summary from a running serve, falling back to
+// loadSummary when none answers.
+func loadViaDaemon(dataPath string) (*TrackerData, error) {
+	path := ipcPath(dataPath)
+	if _, err := os.Stat(path); err != nil {
+		return loadSummary(dataPath)
+	}
+	client := &http.Client{
+		Timeout: 500 * time.Millisecond,
+		Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}},
+	}
+	resp, err := client.Get("http://serve/summary")
+	if err != nil {
+		return loadSummary(dataPath)
+	}
+	defer resp.Body.Close()
+	var c summaryCache
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&c) != nil {
+		return loadSummary(dataPath)
+	}
+	return c.tracker(), nil
+}
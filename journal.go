@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const journalHeading = "## Time tracked"
+
+// expandPath replaces a leading ~ and the strftime-style %Y, %m and %d
+// in a path template with values for day.
+func expandPath(tmpl string, day time.Time) (string, error) {
+	if tmpl == "~" || strings.HasPrefix(tmpl, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		tmpl = filepath.Join(home, tmpl[1:])
+	}
+	return strings.NewReplacer(
+		"%Y", day.Format("2006"),
+		"%m", day.Format("01"),
+		"%d", day.Format("02"),
+		"%%", "%",
+	).Replace(tmpl), nil
+}
+
+// formatHM renders d as "1h 05m".
+func formatHM(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// journalSection renders the sessions that started on day as Markdown.
+func journalSection(tracker *TrackerData, day, now time.Time) string {
+	type row struct {
+		project string
+		e       LogEntry
+	}
+	var rows []row
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			if inRange(e.Start, day, day.AddDate(0, 0, 1)) {
+				rows = append(rows, row{p.Name, e})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].e.Start.Before(rows[j].e.Start) })
+	var b strings.Builder
+	b.WriteString(journalHeading + "\n\n")
+	if len(rows) == 0 {
+		b.WriteString("Nothing tracked.\n")
+		return b.String()
+	}
+	b.WriteString("| Project | Start | End | Duration | Note |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	var total time.Duration
+	for _, r := range rows {
+		end := "running"
+		if !r.e.End.IsZero() {
+			end = r.e.End.Format("15:04")
+		}
+		d := r.e.Duration(now)
+		if r.project != breakProject {
+			total += d
+		}
+		note := strings.ReplaceAll(r.e.Note, "|", `\|`)
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", r.project, r.e.Start.Format("15:04"), end, formatHM(d), note)
+	}
+	fmt.Fprintf(&b, "\n**Total:** %s\n", formatHM(total))
+	return b.String()
+}
+
+// replaceSection swaps an existing journal section in doc for section,
+// or appends section if there is none, so re-running journal the same
+// day updates the note rather than repeating it.
+func replaceSection(doc, section string) string {
+	i := strings.Index(doc, journalHeading+"\n")
+	if i < 0 || (i > 0 && doc[i-1] != '\n') {
+		if doc != "" && !strings.HasSuffix(doc, "\n\n") {
+			if strings.HasSuffix(doc, "\n") {
+				doc += "\n"
+			} else {
+				doc += "\n\n"
+			}
+		}
+		return doc + section
+	}
+	rest := doc[i+len(journalHeading):]
+	end := len(doc)
+	if j := strings.Index(rest, "\n## "); j >= 0 {
+		end = i + len(journalHeading) + j + 1
+		section += "\n"
+	}
+	return doc[:i] + section + doc[end:]
+}
+
+func cmdJournal(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("journal", flag.ContinueOnError)
+	file := fs.String("file", "", "notes file, e.g. ~/notes/daily/%Y-%m-%d.md")
+	dateStr := fs.String("date", "today", "day to write")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	day, err := parseDate(*dateStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	section := journalSection(tracker, day, now)
+	if *file == "" {
+		fmt.Print(section)
+		return
+	}
+	path, err := expandPath(*file, day)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	doc, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Println("Error reading notes:", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Println("Error creating notes directory:", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(replaceSection(string(doc), section)), 0644); err != nil {
+		fmt.Println("Error writing notes:", err)
+		return
+	}
+	fmt.Printf("Wrote %s.\n", path)
+}
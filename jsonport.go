@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// exportJSON writes data in the data file's own JSON schema, so it can be
+// read back with 'import json'. --full writes everything, including
+// invoices and locks, ignoring the filters; otherwise only the selected
+// projects and entries are written.
+//
+// Projects are encoded one at a time and the checksum, which covers the
+// compact encoding of the projects array, is hashed as they go and
+// written last, so the whole document is never held in memory.
+func exportJSON(w io.Writer, tracker *TrackerData, opts exportOptions, full bool) (int, error) {
+	projects := tracker.Projects
+	if !full {
+		projects = nil
+		for _, p := range opts.selectedProjects(tracker) {
+			p.Logs = opts.entries(p)
+			recomputeTotal(&p)
+			projects = append(projects, p)
+		}
+	}
+	h := sha256.New()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "{\"version\":%d,\"projects\":[", dataSchemaVersion)
+	h.Write([]byte("["))
+	entries := 0
+	for i, p := range projects {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 {
+			bw.WriteString(",")
+			h.Write([]byte(","))
+		}
+		bw.WriteString("\n")
+		bw.Write(data)
+		h.Write(data)
+		entries += len(p.Logs)
+	}
+	h.Write([]byte("]"))
+	bw.WriteString("\n]")
+	if full {
+		for _, field := range []struct {
+			name string
+			v    any
+		}{{"invoices", tracker.Invoices}, {"locks", tracker.Locks}} {
+			data, err := json.Marshal(field.v)
+			if err != nil {
+				return 0, err
+			}
+			fmt.Fprintf(bw, ",\n%q:%s", field.name, data)
+		}
+	}
+	fmt.Fprintf(bw, ",\n\"entries\":%d,\"checksum\":%q}\n", entries, hex.EncodeToString(h.Sum(nil)))
+	return entries, bw.Flush()
+}
+
+// readJSONExport reads and verifies a file written by 'export json'.
+func readJSONExport(r io.Reader, name string) (*TrackerData, error) {
+	var t TrackerData
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := verifyTracker(name, &t); err != nil {
+		return nil, err
+	}
+	if t.Version > dataSchemaVersion {
+		return nil, fmt.Errorf("%s uses data schema v%d but this ptracker supports v%d; please upgrade", name, t.Version, dataSchemaVersion)
+	}
+	return &t, nil
+}
+
+// importJSON replaces the data with an export, or merges the export in.
+// Merging matches entries by ID; entries from exports made before IDs
+// existed are matched by content instead.
+func importJSON(dataPath string, tracker *TrackerData, in *TrackerData, replace, yes, force bool, now time.Time) {
+	if replace {
+		previewReplace(tracker, in)
+		if !checkReplaceLocked(tracker, in, force) {
+			return
+		}
+		if !confirm(fmt.Sprintf("Replace all %d entries with %d from the file?", countEntries(tracker), in.Entries), yes) {
+			return
+		}
+		snap, ok := takeSnapshot(dataPath, "import")
+		if !ok {
+			return
+		}
+		if err := saveTracker(dataPath, in); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+		fmt.Printf("Replaced data with %d entries.\n", countEntries(in))
+		printRestoreHint(snap)
+		return
+	}
+	// make sure every local entry has an ID to merge against
+	stampEntries(dataPath, tracker, now)
+	seen := map[string]bool{}
+	digests := map[string]string{}
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			seen[entryHash(p.Name, e)] = true
+			digests[e.ID] = entryDigest(e)
+		}
+	}
+	locked := 0
+	for pi := range in.Projects {
+		p := &in.Projects[pi]
+		logs := p.Logs[:0]
+		for _, e := range p.Logs {
+			// leaving out an entry keeps the local copy, if there is one
+			if !force && lockedAt(tracker, p.Name, e) != nil {
+				if d, ok := digests[e.ID]; !ok || d != entryDigest(e) {
+					locked++
+				}
+				continue
+			}
+			if e.ID == "" {
+				if seen[entryHash(p.Name, e)] {
+					continue
+				}
+				e.ID = newEntryID()
+			}
+			logs = append(logs, e)
+		}
+		p.Logs = logs
+	}
+	before := countEntries(tracker)
+	merged, conflicts := mergeTrackers(tracker, in, &syncBase{Entries: map[string]string{}}, newestWins)
+	snap, ok := takeSnapshot(dataPath, "merge")
+	if !ok {
+		return
+	}
+	if err := saveTracker(dataPath, merged); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Merged: %d entries added", countEntries(merged)-before)
+	if conflicts > 0 {
+		fmt.Printf(", %d changed entries resolved by newest", conflicts)
+	}
+	fmt.Println(".")
+	if locked > 0 {
+		fmt.Printf("Skipped %d entries in locked periods (--force to import them).\n", locked)
+	}
+	printRestoreHint(snap)
+}
+
+func countEntries(tracker *TrackerData) int {
+	n := 0
+	for _, p := range tracker.Projects {
+		n += len(p.Logs)
+	}
+	return n
+}
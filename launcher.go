@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// launcherFormats are the --format values of list for launcher apps.
+var launcherFormats = []string{"alfred", "raycast"}
+
+// alfredItem is an item of an Alfred Script Filter's JSON output. arg is
+// the project name, and the action variable says whether running
+// 'ptracker toggle {query}' will start or stop it.
+type alfredItem struct {
+	UID          string            `json:"uid"`
+	Title        string            `json:"title"`
+	Subtitle     string            `json:"subtitle"`
+	Arg          string            `json:"arg"`
+	Autocomplete string            `json:"autocomplete"`
+	Variables    map[string]string `json:"variables"`
+}
+
+// raycastItem mirrors a Raycast List.Item, with the ptracker arguments
+// that start or stop the project.
+type raycastItem struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Subtitle    string              `json:"subtitle"`
+	Accessories []map[string]string `json:"accessories,omitempty"`
+	Action      string              `json:"action"`
+	Arguments   []string            `json:"arguments"`
+}
+
+// launcherSubtitle describes a project's state and what selecting it
+// does.
+func launcherSubtitle(p Project, now time.Time) (subtitle, action string) {
+	if e := p.running(); e != nil {
+		return fmt.Sprintf("Running for %s; select to stop", formatHM(e.Duration(now))), "stop"
+	}
+	subtitle = formatHM(projectTotal(p, now)) + " tracked"
+	if t := lastActive(p, now); !t.IsZero() {
+		subtitle += ", last active " + formatDate(t)
+	}
+	return subtitle + "; select to start", "start"
+}
+
+// writeLauncher prints projects as Alfred or Raycast JSON.
+func writeLauncher(format string, projects []Project, now time.Time) error {
+	var out any
+	switch format {
+	case "alfred":
+		items := []alfredItem{}
+		for _, p := range projects {
+			subtitle, action := launcherSubtitle(p, now)
+			title := p.Name
+			if p.Icon != "" {
+				title = p.Icon + " " + p.Name
+			}
+			items = append(items, alfredItem{UID: p.Name, Title: title, Subtitle: subtitle, Arg: p.Name,
+				Autocomplete: p.Name, Variables: map[string]string{"action": action}})
+		}
+		out = map[string]any{"items": items}
+	case "raycast":
+		items := []raycastItem{}
+		for _, p := range projects {
+			subtitle, action := launcherSubtitle(p, now)
+			item := raycastItem{ID: p.Name, Title: p.Name, Subtitle: subtitle, Action: action,
+				Arguments: []string{action, p.Name}}
+			if p.Icon != "" {
+				item.Accessories = []map[string]string{{"text": p.Icon}}
+			}
+			items = append(items, item)
+		}
+		out = map[string]any{"items": items}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var listSorts = []string{"name", "time", "last-active"}
+
+// lastActive is when p was last worked on: now for a running session,
+// otherwise the end of its latest entry.
+func lastActive(p Project, now time.Time) time.Time {
+	if len(p.Logs) == 0 {
+		return time.Time{}
+	}
+	last := p.Logs[len(p.Logs)-1]
+	if last.End.IsZero() {
+		return now
+	}
+	return last.End
+}
+
+// nameMatches matches a --filter value against a project name: a glob if
+// it has wildcards, otherwise a case-insensitive substring.
+func nameMatches(filter, name string) bool {
+	if strings.ContainsAny(filter, "*?[") {
+		ok, _ := path.Match(strings.ToLower(filter), strings.ToLower(name))
+		return ok
+	}
+	return strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+func cmdList(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	state := fs.String("state", "", "only projects in this state, or all (default: all but archived)")
+	sortBy := fs.String("sort", "", "order by "+strings.Join(listSorts, ", ")+" (default: creation order)")
+	filter := fs.String("filter", "", "only projects whose name contains this text or matches this glob")
+	format := fs.String("format", "", "print csv, tsv, or alfred or raycast JSON instead of a table")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	keep, err := stateFilter(*state)
+	if err == nil && !slices.Contains(launcherFormats, *format) && checkTableFormat(*format) != nil {
+		err = fmt.Errorf("unknown format '%s'; use %s", *format, strings.Join(slices.Concat(tableFormats, launcherFormats), ", "))
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var projects []Project
+	for _, p := range tracker.Projects {
+		if keep(p) && (*filter == "" || nameMatches(*filter, p.Name)) {
+			projects = append(projects, p)
+		}
+	}
+	switch *sortBy {
+	case "":
+	case "name":
+		sort.SliceStable(projects, func(i, j int) bool { return strings.ToLower(projects[i].Name) < strings.ToLower(projects[j].Name) })
+	case "time":
+		sort.SliceStable(projects, func(i, j int) bool { return projectTotal(projects[i], now) > projectTotal(projects[j], now) })
+	case "last-active":
+		sort.SliceStable(projects, func(i, j int) bool { return lastActive(projects[i], now).After(lastActive(projects[j], now)) })
+	default:
+		fmt.Printf("Unknown sort '%s'. Use %s.\n", *sortBy, strings.Join(listSorts, ", "))
+		return
+	}
+	if slices.Contains(launcherFormats, *format) {
+		if err := writeLauncher(*format, projects, now); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+	if *format != "" {
+		var rows [][]string
+		for _, p := range projects {
+			budget := ""
+			if p.Budget > 0 {
+				budget = minutes(p.Budget)
+			}
+			rows = append(rows, []string{p.Name, p.state(), strconv.Itoa(p.sessionCount()), minutes(projectTotal(p, now)),
+				timestamp(lastActive(p, now)), budget, timestamp(p.Deadline)})
+		}
+		writeTable(os.Stdout, *format, []string{"project", "state", "sessions", "minutes", "last_active", "budget_minutes", "deadline"}, rows)
+		return
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects.")
+		return
+	}
+	fmt.Printf("%-16s | %-9s | %-8s | %-9s | %s\n", "Project", "State", "Sessions", "Time", "Last active")
+	fmt.Println("-----------------|-----------|----------|-----------|------------")
+	for _, p := range projects {
+		last := "never"
+		if t := lastActive(p, now); !t.IsZero() {
+			switch {
+			case config.RelativeTimes && t.Equal(now):
+				last = "now *"
+			case config.RelativeTimes:
+				last = agoLabel(t, now)
+			case t.Equal(now):
+				last = formatDate(t) + " *"
+			default:
+				last = formatDate(t)
+			}
+		}
+		line := fmt.Sprintf("%s | %-9s | %-8d | %9s | %s", projectLabel(p, 16), p.state(), p.sessionCount(), formatHM(projectTotal(p, now)), last)
+		for _, s := range []string{budgetLabel(p, now), deadlineLabel(p, now)} {
+			if s != "" {
+				line += " | " + s
+			}
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// cmdListen serves a tiny plain-text API for macro pads and hotkey tools
+// that can only send simple GET requests. It is open on loopback; on any
+// other address it needs an API token, given as a Bearer header or a
+// ?token= parameter.
+func cmdListen(appDir, dataPath string, args []string) {
+	fs := flag.NewFlagSet("listen", flag.ContinueOnError)
+	port := fs.Int("port", 7777, "port to listen on")
+	bind := fs.String("bind", "127.0.0.1", "address to bind")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	addr := net.JoinHostPort(*bind, strconv.Itoa(*port))
+	var valid func(string) bool
+	if !isLoopback(addr) {
+		var err error
+		if valid, err = tokenChecker(appDir); err != nil {
+			fmt.Println("Error reading tokens:", err)
+			return
+		}
+		if valid == nil {
+			fmt.Println("Refusing to listen on a non-loopback address without API tokens.")
+			fmt.Println("Create one with 'ptracker token create NAME'.")
+			return
+		}
+	}
+	st := newStore(dataPath)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /toggle/{project}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("project")
+		var msg string
+		err := st.update("listen toggle "+name, func(tracker *TrackerData) error {
+			p, started, dur, err := toggleSession(tracker, name, "", false, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			if started {
+				msg = "started " + p.Name
+			} else {
+				msg = "stopped " + p.Name + " " + formatHM(dur)
+			}
+			return nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, msg)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		tracker, err := st.view()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		now := time.Now().UTC()
+		idle := true
+		for _, p := range tracker.Projects {
+			if e := p.running(); e != nil {
+				fmt.Fprintln(w, p.Name, formatHM(e.Duration(now)))
+				idle = false
+			}
+		}
+		if idle {
+			fmt.Fprintln(w, "idle")
+		}
+	})
+	var handler http.Handler = mux
+	if valid != nil {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if t := r.URL.Query().Get("token"); t != "" {
+				r.Header.Set("Authorization", "Bearer "+t)
+			}
+			requireToken(mux, valid).ServeHTTP(w, r)
+		})
+	}
+	fmt.Printf("Listening on http://%s\n", addr)
+	log.Println("listen: listening on", addr, "auth:", valid != nil)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		fmt.Println("Error serving:", err)
+	}
+}
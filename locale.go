@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localeFormat is how dates and decimals are shown for a locale. Input
+// is unaffected: dates are always given as YYYY-MM-DD.
+type localeFormat struct {
+	date    string
+	decimal byte
+}
+
+// isoFormat is used when no locale is set, or for "C" and "POSIX".
+var isoFormat = localeFormat{dateLayout, '.'}
+
+// locales are keyed by language, or language_TERRITORY where the
+// territory changes the format.
+var locales = map[string]localeFormat{
+	"en":    {"01/02/2006", '.'},
+	"en_GB": {"02/01/2006", '.'},
+	"en_IE": {"02/01/2006", '.'},
+	"en_AU": {"02/01/2006", '.'},
+	"en_NZ": {"02/01/2006", '.'},
+	"en_IN": {"02/01/2006", '.'},
+	"en_ZA": {"2006/01/02", ','},
+	"en_CA": {"2006-01-02", '.'},
+	"de":    {"02.01.2006", ','},
+	"de_CH": {"02.01.2006", '.'},
+	"fr":    {"02/01/2006", ','},
+	"fr_CA": {"2006-01-02", ','},
+	"fr_CH": {"02.01.2006", '.'},
+	"es":    {"02/01/2006", ','},
+	"it":    {"02/01/2006", ','},
+	"pt":    {"02/01/2006", ','},
+	"nl":    {"02-01-2006", ','},
+	"da":    {"02.01.2006", ','},
+	"nb":    {"02.01.2006", ','},
+	"fi":    {"02.01.2006", ','},
+	"sv":    {"2006-01-02", ','},
+	"pl":    {"02.01.2006", ','},
+	"cs":    {"02.01.2006", ','},
+	"ru":    {"02.01.2006", ','},
+	"tr":    {"02.01.2006", ','},
+	"ja":    {"2006/01/02", '.'},
+	"zh":    {"2006/01/02", '.'},
+	"ko":    {"2006.01.02", '.'},
+}
+
+// currentLocale picks the format for "locale" in config, or else for
+// LC_ALL, LC_TIME or LANG, e.g. "de_DE.UTF-8".
+func currentLocale() localeFormat {
+	name := config.Locale
+	for _, v := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if name != "" {
+			break
+		}
+		name = os.Getenv(v)
+	}
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "-", "_")
+	if f, ok := locales[name]; ok {
+		return f
+	}
+	lang, _, _ := strings.Cut(name, "_")
+	if f, ok := locales[strings.ToLower(lang)]; ok {
+		return f
+	}
+	return isoFormat
+}
+
+// formatDate shows a day in the locale's date format.
+func formatDate(t time.Time) string {
+	return t.Format(currentLocale().date)
+}
+
+// formatDecimal renders f with prec decimals and the locale's separator.
+func formatDecimal(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	if d := currentLocale().decimal; d != '.' {
+		s = strings.Replace(s, ".", string(d), 1)
+	}
+	return s
+}
+
+// signedDecimal is formatDecimal with a sign, for differences.
+func signedDecimal(f float64, prec int) string {
+	if f < 0 {
+		return formatDecimal(f, prec)
+	}
+	return "+" + formatDecimal(f, prec)
+}
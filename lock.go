@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const lockTimeout = 10 * time.Second
+
+// lockData takes an exclusive lock on the data file shared by the CLI and
+// the daemon, so a load-modify-save cycle can't interleave with another.
+// The lock is the operating system's (see tryLock), so it is released if
+// the process dies and is never mistaken for a stale one while held.
+func lockData(dataPath string) (unlock func(), err error) {
+	name := dataPath + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, ok, err := tryLock(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return unlock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("data file is locked by another ptracker process (%s)", name)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// heldLock is a lock kept for the rest of a command, with the storage it
+// guards so it can be dropped and taken again around a prompt.
+type heldLock struct {
+	storage Storage
+	unlock  func()
+}
+
+var heldLocks []*heldLock
+
+// holdLock locks storage for the rest of the command. Prompts read their
+// answer with readAnswer, which lets go of the lock while the user thinks.
+func holdLock(storage Storage) (release func(), err error) {
+	unlock, err := storage.Lock()
+	if err != nil {
+		return nil, err
+	}
+	h := &heldLock{storage: storage, unlock: unlock}
+	heldLocks = append(heldLocks, h)
+	return func() {
+		for i, x := range heldLocks {
+			if x == h {
+				heldLocks = append(heldLocks[:i], heldLocks[i+1:]...)
+				break
+			}
+		}
+		h.unlock()
+	}, nil
+}
+
+// readAnswer reads a line from in with the held locks released, so
+// other processes aren't kept waiting on the user. If the data changed
+// meanwhile the loaded copy is out of date and saving it would lose that
+// change, so the command stops there.
+func readAnswer(in *bufio.Reader) (string, error) {
+	revisions := make([]string, len(heldLocks))
+	for i, h := range heldLocks {
+		revisions[i], _ = h.storage.Watch()
+		h.unlock()
+	}
+	line, err := in.ReadString('\n')
+	changed := false
+	for i, h := range heldLocks {
+		unlock, lerr := h.storage.Lock()
+		if lerr != nil {
+			fmt.Println(lerr)
+			os.Exit(1)
+		}
+		h.unlock = unlock
+		if rev, _ := h.storage.Watch(); rev != revisions[i] {
+			changed = true
+		}
+	}
+	if changed {
+		fmt.Println("The data changed while waiting for an answer; nothing was saved. Run the command again.")
+		log.Println("aborted: data changed during prompt")
+		for _, h := range heldLocks {
+			h.unlock()
+		}
+		os.Exit(1)
+	}
+	return line, err
+}
@@ -0,0 +1,39 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// tryLock creates name exclusively where there is no flock. The file
+// holds a token naming this holder, and unlock only removes it if the
+// token is still there, so a lock taken over by another process is left
+// alone. A lock file older than lockStale is assumed to be left behind
+// by a crashed process; prompts don't hold the lock (see readAnswer), so
+// a live holder never keeps it that long.
+const lockStale = time.Minute
+
+func tryLock(name string) (unlock func(), ok bool, err error) {
+	token := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		if fi, err := os.Stat(name); err == nil && time.Since(fi.ModTime()) > lockStale {
+			os.Remove(name)
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	f.WriteString(token)
+	f.Close()
+	return func() {
+		if b, err := os.ReadFile(name); err == nil && string(b) == token {
+			os.Remove(name)
+		}
+	}, true, nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sourceMail = "mail"
+
+// workLine matches a line of a mail such as "worked 2h on acme, 3–5pm",
+// "acme 09:00-10:30 yesterday: review" or "90m acme". The submatches are
+// the length, project, start, start am/pm, end, end am/pm, day and note.
+var workLine = regexp.MustCompile(`(?i)^(?:(?:worked|spent)\s+)?` +
+	`(?:(\d+(?:\.\d+)?h(?:\s*\d+\s*m(?:in)?)?|\d+\s*m(?:in)?)\s+)?` +
+	`(?:on\s+)?([^\s,;:]+)` +
+	`(?:[\s,;]+(\d{1,2}(?::\d{2})?)\s*(am|pm)?\s*(?:-|–|—|to)\s*(\d{1,2}(?::\d{2})?)\s*(am|pm)?)?` +
+	`(?:[\s,;]+(today|yesterday|\d{4}-\d{2}-\d{2}))?` +
+	`\s*(?::\s*(.*))?$`)
+
+var replyPrefix = regexp.MustCompile(`(?i)^\s*((re|fwd?|aw|wg):\s*)+`)
+
+type mailEntry struct {
+	project    string
+	note       string
+	start, end time.Time
+}
+
+// parseWorkLine reads an entry from a line of a mail sent at sent. Lines
+// with neither a length nor a time range aren't entries (ok is false).
+// Without a range the entry ends when the mail was sent.
+func parseWorkLine(line string, sent time.Time) (e mailEntry, ok bool, err error) {
+	m := workLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil || (m[1] == "" && m[3] == "") {
+		return e, false, nil
+	}
+	e.project, e.note = m[2], strings.TrimSpace(m[8])
+	if m[3] == "" {
+		if m[7] != "" {
+			return e, true, errors.New("a day needs a time range, e.g. 3-5pm")
+		}
+		length := strings.NewReplacer(" ", "", "min", "m").Replace(strings.ToLower(m[1]))
+		d, err := time.ParseDuration(length)
+		if err != nil || d <= 0 {
+			return e, true, fmt.Errorf("invalid length %q", m[1])
+		}
+		e.end = sent.Truncate(time.Minute)
+		e.start = e.end.Add(-d)
+		return e, true, nil
+	}
+	day, _ := parseDate("today", sent)
+	if m[7] != "" {
+		if day, err = parseDate(strings.ToLower(m[7]), sent); err != nil {
+			return e, true, err
+		}
+	}
+	startMer, endMer := strings.ToLower(m[4]), strings.ToLower(m[6])
+	end, err := clockMinutes(m[5], endMer)
+	if err != nil {
+		return e, true, err
+	}
+	start, err := clockMinutes(m[3], cmp.Or(startMer, endMer))
+	if err != nil {
+		return e, true, err
+	}
+	if startMer == "" && endMer == "pm" && start >= end {
+		// "11-1pm" starts in the morning
+		start -= 12 * 60
+	}
+	if start >= end {
+		return e, true, errors.New("end must be after start")
+	}
+	e.start = day.Add(time.Duration(start) * time.Minute)
+	e.end = day.Add(time.Duration(end) * time.Minute)
+	return e, true, nil
+}
+
+// clockMinutes converts "3", "3:30" or "15:30" with an optional "am" or
+// "pm" to minutes after midnight.
+func clockMinutes(hm, meridiem string) (int, error) {
+	h, mm, _ := strings.Cut(hm, ":")
+	hour, _ := strconv.Atoi(h)
+	minute := 0
+	if mm != "" {
+		minute, _ = strconv.Atoi(mm)
+	}
+	if meridiem != "" {
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("invalid time %q", hm+meridiem)
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q", hm+meridiem)
+	}
+	return hour*60 + minute, nil
+}
+
+// readMail returns a message's sender, date and the lines of its subject
+// and plain-text body, leaving out quoted lines and the signature.
+func readMail(r io.Reader, now time.Time) (from string, sent time.Time, lines []string, err error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return "", sent, nil, err
+	}
+	if addr, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		from = strings.ToLower(addr.Address)
+	}
+	sent = now
+	if t, err := msg.Header.Date(); err == nil && t.Before(now) {
+		sent = t.UTC()
+	}
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	lines = append(lines, replyPrefix.ReplaceAllString(subject, ""))
+	body, err := plainText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return from, sent, nil, err
+	}
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r ")
+		if line == "--" {
+			break
+		}
+		if !strings.HasPrefix(line, ">") {
+			lines = append(lines, line)
+		}
+	}
+	return from, sent, lines, sc.Err()
+}
+
+// plainText returns the first text/plain part of a body.
+func plainText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return "", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := plainText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil || text != "" {
+				return text, err
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", nil
+	}
+	switch strings.ToLower(encoding) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	return string(data), err
+}
+
+// mailAllowed reports whether entries may be created from a sender.
+func mailAllowed(from string) bool {
+	return len(config.MailFrom) == 0 || slices.ContainsFunc(config.MailFrom, func(a string) bool {
+		return strings.EqualFold(a, from)
+	})
+}
+
+// applyMail adds the entries found in a mail's lines. It returns a line
+// per added entry and per line that couldn't be added.
+func applyMail(tracker *TrackerData, lines []string, sent, now time.Time, dryRun bool) (added, skipped []string) {
+	for _, line := range lines {
+		e, ok, err := parseWorkLine(line, sent)
+		if !ok {
+			continue
+		}
+		p := findProject(tracker, e.project)
+		switch {
+		case err != nil:
+		case p == nil:
+			err = notFoundError(e.project)
+		case e.end.After(now):
+			err = errors.New("ends in the future")
+		case lockedAt(tracker, p.Name, LogEntry{Start: e.start}) != nil:
+			err = errors.New("locked period")
+		case findOverlap(p, e.start, e.end, -1, now) >= 0:
+			err = errors.New("overlaps an entry")
+		}
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%q: %v", strings.TrimSpace(line), err))
+			continue
+		}
+		if !dryRun {
+			insertEntry(p, LogEntry{Start: e.start, End: e.end, Note: e.note, User: currentUser(), Source: sourceMail})
+			recomputeTotal(p)
+		}
+		added = append(added, fmt.Sprintf("'%s' %s %s-%s (%s)", p.Name, formatDate(e.start),
+			e.start.Format(clockLayout(false)), e.end.Format(clockLayout(false)), formatHM(e.end.Sub(e.start))))
+	}
+	return added, skipped
+}
+
+// cmdMail creates entries from a mail read from a file or standard input,
+// e.g. piped in by procmail.
+func cmdMail(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("mail", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show the entries without adding them")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	in := io.Reader(os.Stdin)
+	if len(pos) > 0 {
+		f, err := os.Open(pos[0])
+		if err != nil {
+			fmt.Println("Error reading mail:", err)
+			return
+		}
+		defer f.Close()
+		in = f
+	}
+	from, sent, lines, err := readMail(in, now)
+	if err != nil {
+		fmt.Println("Error reading mail:", err)
+		return
+	}
+	if !mailAllowed(from) {
+		fmt.Printf("Ignoring mail from %s; it is not in \"mail_from\".\n", from)
+		return
+	}
+	added, skipped := applyMail(tracker, lines, sent, now, *dryRun)
+	if len(added) > 0 && !*dryRun {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	verb := "Added"
+	if *dryRun {
+		verb = "Would add"
+	}
+	for _, a := range added {
+		fmt.Println(verb, a)
+	}
+	for _, s := range skipped {
+		fmt.Println("Skipped", s)
+	}
+	if len(added)+len(skipped) == 0 {
+		fmt.Println("No entries found.")
+	}
+}
+
+type mailResponse struct {
+	Added   []string `json:"added"`
+	Skipped []string `json:"skipped"`
+}
+
+// handleMail creates entries from a raw RFC 822 message in the body.
+func (s *apiServer) handleMail(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	from, sent, lines, err := readMail(r.Body, now)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if !mailAllowed(from) {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: "sender not in mail_from"})
+		return
+	}
+	resp := mailResponse{Added: []string{}, Skipped: []string{}}
+	err = s.store.update("mail from "+from, func(tracker *TrackerData) error {
+		added, skipped := applyMail(tracker, lines, sent, now, false)
+		resp = mailResponse{Added: append([]string{}, added...), Skipped: append([]string{}, skipped...)}
+		if len(added) == 0 {
+			return errNothingDue
+		}
+		return nil
+	})
+	if err != nil && err != errNothingDue {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
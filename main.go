@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -13,80 +13,425 @@ const helpText = `ptracker - Personal Time Tracker CLI
 Track time spent on your projects with simple commands.
 
 USAGE:
-  ptracker [COMMAND] [OPTIONS]
+  ptracker [COMMAND] [OPTIONS] [--profile NAME] [--read-only] [--12h|--24h]
 
 COMMANDS:
   create [project]       Create a new project
   delete [project]       Delete a project and all its logs
-  start [project]        Start tracking time on a project
-  stop [project]         Stop tracking the specified project
-  status                 Show active tracking sessions
+  start [project]        Start tracking time on a project (--note to describe it,
+                         --tag to tag it, --for 45m to have serve stop it and
+                         notify you); @name starts a template from config
+  stop [project...]      Stop tracking the given projects (--note to describe it);
+                         globs such as "client-*" match several
+  add [project]          Add a finished entry (--start, --end, --note, --tag)
+  edit [project] [#]     Change an entry (--start, --end, --note)
+  adjust [project] [#]   Move an entry's --start or --end by a relative amount
+                         (e.g. +10m, -5m), or --shift all of it; # defaults to
+                         the latest entry
+  bulk                   Change every entry matching --project, --from, --to and
+                         --tag at once, after a preview: --add-tag,
+                         --remove-tag, --set-billable, --set-non-billable, --note
+  pause [project]        Pause the active session (e.g. for lunch)
+  resume [project]       Resume a paused session
+  break [duration]       Start a break, or record a finished one (e.g. 15m)
+  toggle [project]       Start the project if idle, stop it if active; without a
+                         project, toggles the one used last (--note)
+  status                 Show active tracking sessions (--project to show one and
+                         exit 1 if it is idle, --quiet to only set the exit
+                         code, --short for one line such as "acme 1h05m")
+  current                Print the running project's name (--json for
+                         project, start, seconds and paused)
+  elapsed [project]      Print the running session's length as H:MM:SS
+                         (--seconds, --minutes)
+  prompt                 Print a colored segment for a shell prompt, or nothing
+                         when idle (--shell zsh|bash, --format, --no-color)
   stats [project]        View time log for a project
+                         (--last N, --since DATE, --reverse for newest first,
+                         --format csv|tsv; --all for every project in one
+                         chronological log); on a terminal, output taller
+                         than the screen goes through $PAGER (--no-pager)
+  annotate [project] [#] [note]
+                         Set the note on an existing session
+  amend [note]           Set the note on the most recently stopped session
+  search [text]          Search session notes across all projects
+                         (--regex, --from, --to, --project)
+  today                  Show time tracked today per project
+  timeline               Draw the day hour by hour in 5-minute blocks to show
+                         which project filled each block and where the gaps
+                         are (--date)
+  fill                   Go through the day's untracked gaps and assign each to
+                         a project (with an optional note) or a break (--date,
+                         --min 5m)
+  apply-recurring        Add entries for the recurring blocks in config that
+                         have finished today (--from, --to); serve does this
+                         automatically
   report                 Show a summary of total time spent across all projects
-  list                   List all tracked projects
+                         (--from, --to, --meta k=v to filter by project metadata,
+                         --state, --by-user, --include-archives, --group-by
+                         day|week|month|period|quarter; weeks are labeled with
+                         ISO week numbers, periods and quarters follow
+                         "fiscal"; --rounded compares billed and actual time,
+                         --raw; --profitability shows revenue, cost and margin;
+                         --stale 30d lists projects idle that long; --compare
+                         A B puts two periods side by side, each this-week,
+                         last-week, this-month, last-month, a date or FROM..TO;
+                         --trend shows hours per week over --window 8w;
+                         --month forecasts this month at the current pace;
+                         --format csv|tsv prints the summary for other tools;
+                         --verify checks that day buckets, the index and
+                         percentages agree with the totals)
+  digest                 Render last week's report as HTML (--email addr to send
+                         it via the "smtp" config, --from, --to); --discord
+                         posts today's summary to the "discord" webhook
+  journal                Write the day's sessions as a Markdown section into a
+                         notes file (--file, which may contain strftime-style
+                         date codes, --date); re-running replaces the section
+  export [format]        Export entries (--from, --to, --project, -o):
+                         obsidian  Markdown notes with YAML frontmatter for
+                                   Dataview, one per project or --by day
+                         xlsx      Excel workbook, a sheet per project and a
+                                   summary sheet with formulas
+                         json      The data file's own schema; --full exports
+                                   everything, for backup and migration
+                         csv       One row per entry, as read by 'import csv'
+                         json, csv and --template stream entries as they are
+                         written; --compress gzips them (import reads .gz)
+                         --template FILE renders a Go text/template with
+                         .Entries, .Projects and totals (see README)
+                         --anonymize hashes project, user and tag names and
+                         drops notes and money, keeping timestamps, for sharing
+  push notion [--database ID]
+                         Create a Notion database row per closed session; rows
+                         already pushed are skipped (--from, --to, --dry-run).
+                         Without --database, each project's notion.database
+                         metadata picks where its sessions go
+  list                   List projects with their state, sessions, total time, last
+                         activity, budget and deadline (--sort name|time|
+                         last-active, --filter text or glob, --state, --format
+                         csv|tsv|alfred|raycast; archived projects are hidden
+                         unless asked for)
+  invoice create [project]
+                         Bill the project's uninvoiced, billable entries at its
+                         rate (--from, --to, --number) and record it in the
+                         ledger
+  invoices [list|mark-paid N]
+                         Show issued invoices and outstanding amounts
+  expense [add|list|rm] [project]
+                         Record costs billed with a project's time (--date)
+  lock [list|remove #]   Lock entries against changes (--from, --to, --project,
+                         or --invoice N for the entries it billed); add, edit,
+                         annotate, amend, delete, import and restore then need
+                         --force. remove asks first (--yes)
+  set [project]          Set project options (--color, --icon, --desc, --meta k=v,
+                         --rate hourly rate, --cost-rate, --budget 40h,
+                         --deadline YYYY-MM-DD, --state active|paused|completed|
+                         archived)
+  archive [project...]   Archive the given projects (or globs) in one go, leaving
+                         them out of list and report
+  serve                  Run the tracker daemon with an HTTP API (--listen addr)
+                         Exposes Prometheus metrics at /metrics and a JSON API
+                         under /v1 (start, stop, status[?stream=1], report,
+                         mail), the gRPC service ptracker.v1.Tracker (Start,
+                         Stop, Report and streaming Status; see
+                         proto/ptracker/v1/tracker.proto), POST /intent for
+                         voice assistants and POST /heartbeat for editor
+                         plugins; /ui/ is a mobile remote control that
+                         installs as an app. With
+                         "autotrack" in config it follows the focused window;
+                         with "on_lock" it pauses or stops sessions on
+                         screen lock and suspend. --tls-cert/--tls-key for
+                         HTTPS; see 'token' for authentication. --openapi
+                         prints an OpenAPI 3 document for the API. For
+                         Grafana it answers the SimpleJSON protocol at / and
+                         GET /query?from=&to=&interval=1h for Infinity.
+                         While it runs, status, today, prompt, current and
+                         elapsed ask it over serve.sock instead of reading
+                         the data file.
+  listen                 Serve plain GET /toggle/PROJECT and /status for macro
+                         pads and hotkeys (--port 7777, --bind 127.0.0.1); off
+                         loopback it needs an API token (?token= works)
+  token [create|list|revoke] [name]
+                         Manage API tokens; once one exists, serve requires
+                         "Authorization: Bearer TOKEN" on every request
+  daemon [install|uninstall]
+                         Run serve at login via systemd (Linux) or launchd (macOS)
+  shortcuts              Write a Termux:Widget toggle script per project
+  bot telegram           Run a Telegram bot answering /start_tracking PROJECT,
+                         /stop [PROJECT], /today and /status (--token; serve
+                         runs it when "telegram" is in config)
+  profile [list|create|switch] [name]
+                         Manage separate data profiles (e.g. work, personal)
+  doctor --overlaps      List overlapping entries within projects
+  dedupe                 Find entries with (nearly) the same start and end in a
+                         project and remove them (--tolerance 1m, --project,
+                         --yes to remove without asking)
+  import [csv|toggl|json] [file]
+                         Import entries; entries already present are skipped,
+                         as are ones in locked periods unless --force.
+                         json takes --replace or --merge
+  mail [file]            Add entries from a mail on stdin (e.g. from procmail)
+                         with lines like "worked 2h on acme, 3-5pm: notes"
+                         (--dry-run)
+  sync --remote [path|url]
+                         Two-way merge with another data file or a sync URL
+                         (--policy newest|local|remote|interactive for entries
+                         changed on both sides; --token or "sync_token" in
+                         config for a server URL)
+  server --store DIR     Run a self-hosted sync server at /sync (--listen addr,
+                         --add-user NAME to issue a token, --tls-cert and
+                         --tls-key; plain HTTP only on loopback unless
+                         --insecure)
+  compact --before DATE  Move older entries into per-year archive files
+  restore [file]         Replace the data with a snapshot or backup from
+                         backups/ (without a file, list them; --force if that
+                         changes locked entries)
+  convert --format [binary|json]
+                         Switch the data file encoding (binary is smaller and faster)
+  gen FILE               Write synthetic data to a new file (.bin for binary) for
+                         profiling or demos (--projects 50, --entries 100000,
+                         --seed 42, --end 2025-01-01; the same flags give the
+                         same file)
+  version                Show version, build and data schema information
+  schema [data|config|api]
+                         Print the JSON Schema of the data file (and 'export
+                         json'), config.json or the API bodies
   help                   Show this help message
 
 EXAMPLES:
   ptracker create my_website
   ptracker start my_website
-  ptracker stop my_website
+  ptracker start @standup
+  ptracker start my_website --for 45m
+  ptracker toggle my_website
+  ptracker status --project my_website --quiet && ptracker stop my_website
+  ptracker stop my_website --note "fixed nav layout"
+  ptracker add my_website --start "2024-05-01 09:00" --end "2024-05-01 11:30"
+  ptracker edit my_website 3 --end 17:45
+  ptracker adjust my_website 3 --start +10m --end -5m
+  ptracker bulk --project my_website --from 2024-01-01 --add-tag client-review
+  ptracker annotate my_website 3 "client call"
+  ptracker stop .
+  ptracker archive "experiment-*" old-site
+  ptracker amend "reviewed PRs"
+  ptracker search "invoice" --from 2024-01-01
   ptracker stats my_website
+  ptracker fill --date yesterday
+  ptracker apply-recurring --from 2024-05-01
   ptracker report
+  ptracker set my_website --color cyan --icon 🌐
+  ptracker set my_website --desc "Personal site" --meta client=acme
+  ptracker set my_website --rate 85
+  ptracker set my_website --cost-rate 40
+  ptracker set my_website --budget 40h --deadline 2024-06-30
+  ptracker set my_website --state completed
+  ptracker expense add my_website 42.50 "domain renewal"
+  ptracker list --sort last-active --filter 'client-*'
+  ptracker report --meta client=acme
+  ptracker report --from last-week --group-by week
+  ptracker report --stale 30d
+  ptracker report --compare this-week last-week
+  ptracker report --trend --window 4w
+  ptracker report --month
+  ptracker export json --full --anonymize -o bug-report.json
+  ptracker stats --all --format tsv | awk -F'\t' '{print $1, $5}'
+  ptracker digest --email me@example.com
+  ptracker export obsidian -o ~/vault/time --by day
+  ptracker export xlsx --from 2024-05-01 --to 2024-05-31 -o timesheet.xlsx
+  ptracker export json --full -o backup.json
+  ptracker export csv --from 2020-01-01 --compress -o history.csv
+  ptracker import json backup.json --merge
+  ptracker server --store /var/lib/ptracker --add-user ann
+  ptracker sync --remote https://sync.example.net/sync --token TOKEN
 
 NOTES:
 - Time is automatically recorded using UTC.
 - Multiple projects can have active sessions simultaneously.
+- Settings are read from ~/.ptracker/config.json, e.g. {"overlap": "warn"}
+  to allow overlapping manual entries.
+- Data and config live in ~/.ptracker, or %APPDATA%\ptracker on Windows
+  when there is no ~/.ptracker yet; PTRACKER_DIR overrides both, e.g. to
+  keep them on synced storage.
+- Under Termux, alerts use termux-notification (pkg install termux-api) and
+  'shortcuts' writes a Termux:Widget button per project to ~/.shortcuts.
+- --profile NAME (or PTRACKER_PROFILE) selects a separate data file;
+  'profile switch' changes the default.
+- Entries record the OS username (or "user" from config) so a shared data
+  file can be reported with 'report --by-user'.
+- --12h (or "clock": "12h" in config) shows AM/PM times in status, stats
+  and timeline; --24h overrides the config.
+- "relative_times": true in config shows times in status, stats and list
+  as e.g. "12 min ago" or "3 days ago" instead of dates and times.
+- --read-only (or "read_only": true in config) allows only reporting
+  commands, e.g. for a data file on a read-only mount.
+- The data file carries a checksum and is backed up at most hourly to
+  backups/ (see "backup_keep" and "backup_interval" in config). A corrupted
+  file is refused, with an offer to restore the latest good backup.
+- delete, compact, restore and import json snapshot the data file to
+  backups/ first, whatever the backup settings, and print the restore
+  command that undoes them. compact snapshots archives/ as well, and
+  restoring that snapshot rolls them back too.
+- delete, import json --replace, restore, compact, expense rm, lock
+  remove, bulk and dedupe show what they will remove or change and ask
+  first. --yes goes ahead without asking; without a terminal on stdin,
+  e.g. in a script, they refuse unless given. --force only overrides
+  locked periods and still asks.
+- "storage" in config picks the storage driver by name; "file" (data.json
+  or data.bin) is the default and the only one built in.
+- "wal": true in config writes each save of data.json to data.json.wal
+  first; a save cut short is replayed the next time the data is loaded.
+- Projects with a rate show earnings in 'status' and 'today'; set
+  "currency" in config, e.g. "EUR" or "$".
+- Reports, list and --format csv|tsv show dates and decimals for "locale"
+  in config (e.g. "de_DE"), else LC_ALL, LC_TIME or LANG; CSV switches to
+  ';' where the comma is the decimal mark. Dates are always entered as
+  YYYY-MM-DD, and export keeps ISO dates.
+- Dates accept this-week and last-week; weeks start on Monday unless
+  "week_start": "sunday" is set in config.
+- "fiscal" in config sets the fiscal year for --group-by period and quarter,
+  e.g. {"fiscal": {"year_start": "04-01", "pattern": "4-4-5"}}; without a
+  pattern each period is a month.
+- Projects with a budget show time used and left in list, status and
+  report; start, stop and serve warn from 80% on ("budget_warn": 0.9 in
+  config to change it).
+- Cost rates come from 'set --cost-rate' or per user from "cost_rates" in
+  config, e.g. {"cost_rates": {"ann": 45}}, for 'report --profitability'.
+- "rounding" in config rounds each entry on invoices, e.g. {"rounding":
+  {"increment": "15m", "mode": "up"}}; add "reports": true to make
+  'report' show billed vs actual time unless --raw is given.
+- Templates in config preset a session's project, note, tags and billable
+  flag: {"templates": {"standup": {"project": "ops", "note": "daily standup",
+  "tags": ["meeting"], "billable": false}}}
+- Recurring blocks in config become entries once over, unless the project
+  already has time then: {"recurring": [{"name": "standup", "project": "ops",
+  "days": "weekdays", "start": "09:30", "end": "09:45"}]}
+- "webhook" in config receives every start and stop as a JSON POST; a
+  project's webhook metadata (set --meta webhook=URL) overrides it.
+- With "mqtt" in config, every start and stop is published as JSON to
+  TOPIC/event and the active sessions to TOPIC/status (retained), e.g.
+  {"mqtt": {"broker": "mqtt://homeassistant.local", "topic": "ptracker"}}
+  Add "home_assistant": true for auto-discovered Home Assistant sensors
+  (active project, time tracked today), kept current by serve.
+- With "otlp" in config, e.g. {"otlp": {"endpoint": "http://localhost:4318"}},
+  each stopped session is sent as an OpenTelemetry span: the project is the
+  service, the note names the span, and user, tags and billable are
+  attributes.
+- serve alerts with a desktop notification when a timebox runs out or a
+  project nears or passes its budget; set e.g. {"alerts": {"timebox":
+  "bell,sound:/path/to/ding.wav", "budget": "none"}} to ring the terminal
+  bell, play a sound or stay quiet instead.
+- For the Telegram bot, create one with @BotFather and set {"telegram":
+  {"token": "...", "chat_id": 12345}}; the bot replies with the chat id to
+  use when any other chat writes to it. Add "telegram" to an alert, e.g.
+  {"alerts": {"timebox": "notify,telegram"}}, to be told there too.
+- With "discord" in config, e.g. {"discord": {"webhook": "https://discord.com/
+  api/webhooks/...", "at": "17:30"}}, serve posts the day's summary to the
+  channel at that time (default 18:00).
+- Sessions left running over 24h are closed 8h after they started (or
+  when paused) and marked auto-closed, after asking, before the next
+  command that changes data; set e.g. {"dangling": {"after": "12h",
+  "length": "4h", "policy": "close"}} in config, or "policy": "ignore".
+- 'current' and 'elapsed' are for scripts and automation tools: they print
+  exactly one line, or nothing when idle, with messages on stderr, and exit
+  0 when tracking, 1 when idle and 2 on errors.
+- 'prompt' prints the running project's icon, name and elapsed time in its
+  color. "prompt" in config (or --format) changes the layout: the words
+  project, elapsed and icon preceded by a percent sign are replaced. In
+  zsh (setopt prompt_subst): PROMPT='$(ptracker prompt --shell zsh) %~ %# ';
+  in bash: PS1='$(ptracker prompt --shell bash) \w \$ '
+- 'list --format alfred' is an Alfred Script Filter: each item's arg is the
+  project and the "action" variable says start or stop, so a Run Script
+  action of 'ptracker toggle "{query}"' completes it. --format raycast
+  prints the same as items with "arguments" such as ["stop", "acme"].
+- POST /intent takes {"action": "start", "project": "thesis"} (start, stop,
+  toggle, status or today) and answers {"speech": "Started thesis."} for a
+  Siri Shortcut or assistant webhook to read out; the project may be
+  misheard a little, e.g. "the thesis" or "theses".
+- 'mail' reads the subject and body lines as [LENGTH] [on] PROJECT
+  [START-END] [DAY] [: note], e.g. "1h30m acme" (ending when the mail was
+  sent) or "acme 9-11:30am yesterday: review"; times are UTC. Set
+  {"mail_from": ["me@example.com"]} to accept only your own mails, e.g.
+  with the procmail recipe ":0 w" / "* ^To:.*time@" / "| ptracker mail".
+- Wherever a project is expected, "." or "current" stands for the running
+  project when exactly one is running, e.g. 'ptracker stop .' or
+  'ptracker annotate . "call with Ann"' for the running session.
+- Breaks are tracked under the built-in 'break' project and reported separately.
 
 Happy tracking.`
 
 type LogEntry struct {
+	// ID and Modified let sync merge copies of the data entry by entry.
+	ID       string    `json:"id,omitempty"`
+	Modified time.Time `json:"modified,omitzero"`
+
 	Start time.Time `json:"start"`
 	End   time.Time `json:"end"`
+	Note  string    `json:"note,omitempty"`
+	User  string    `json:"user,omitempty"`
+	Tags  []string  `json:"tags,omitempty"`
+	// Billable is nil for the default, billable.
+	Billable *bool `json:"billable,omitempty"`
+	// Until is when a session started with --for is stopped by serve.
+	Until time.Time `json:"until,omitzero"`
+	// Source is set for entries not created by hand, e.g. "heartbeat".
+	Source string `json:"source,omitempty"`
+	// AutoClosed marks a session closed by the dangling session repair
+	// rather than stopped.
+	AutoClosed bool `json:"autoClosed,omitempty"`
+	// Invoice is the number of the invoice that billed this entry.
+	Invoice string `json:"invoice,omitempty"`
+	// Pushed maps each push destination to the entry's ID there.
+	Pushed map[string]string `json:"pushed,omitempty"`
+
+	Pauses []Pause `json:"pauses,omitempty"`
 }
 
 type Project struct {
 	Name      string        `json:"name"`
 	Logs      []LogEntry    `json:"logs"`
 	TotalTime time.Duration `json:"totalTime"`
+	Color     string        `json:"color,omitempty"`
+	Icon      string        `json:"icon,omitempty"`
+
+	Description string            `json:"description,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty"`
+	// Rate is the hourly rate billed for the project, in config currency.
+	Rate     float64   `json:"rate,omitempty"`
+	Expenses []Expense `json:"expenses,omitempty"`
+	// CostRate is the internal hourly cost of time on the project.
+	CostRate float64 `json:"costRate,omitempty"`
+	// Budget is the time estimated for the whole project.
+	Budget time.Duration `json:"budget,omitempty"`
+	// Deadline is the day the project is due.
+	Deadline time.Time `json:"deadline,omitzero"`
+	// State is one of projectStates; empty means active.
+	State string `json:"state,omitempty"`
+
+	// sessions is the entry count when Logs was trimmed by loadSummary.
+	sessions int
 }
 
 type TrackerData struct {
-	Projects []Project `json:"projects"`
+	Version  int            `json:"version,omitempty"`
+	Checksum string         `json:"checksum,omitempty"`
+	Entries  int            `json:"entries,omitempty"`
+	Projects []Project      `json:"projects"`
+	Invoices []Invoice      `json:"invoices,omitempty"`
+	Locks    []lockedPeriod `json:"locks,omitempty"`
 }
 
-func getAppPaths() (dataPath, logPath string, err error) {
-	home, err := os.UserHomeDir()
+func getAppDir() (string, error) {
+	dir, err := appDirPath()
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
-	dir := filepath.Join(home, ".ptracker")
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", "", err
-	}
-	return filepath.Join(dir, "data.json"), filepath.Join(dir, "ptracker.log"), nil
-}
-
-func loadTracker(filename string) (*TrackerData, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &TrackerData{}, nil
-		}
-		return nil, err
+		return "", err
 	}
-	var tracker TrackerData
-	if err := json.Unmarshal(data, &tracker); err != nil {
-		return nil, err
-	}
-	return &tracker, nil
+	return dir, nil
 }
 
-func saveTracker(filename string, tracker *TrackerData) error {
-	data, err := json.MarshalIndent(tracker, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filename, data, 0644)
+func getAppPaths(dir, profile string) (dataPath, logPath, configPath string) {
+	return resolveDataPath(profileDir(dir, profile)), filepath.Join(dir, "ptracker.log"), filepath.Join(dir, "config.json")
 }
 
 func projectExists(tracker *TrackerData, name string) bool {
@@ -98,37 +443,116 @@ func projectExists(tracker *TrackerData, name string) bool {
 	return false
 }
 
+func findProject(tracker *TrackerData, name string) *Project {
+	for i := range tracker.Projects {
+		if tracker.Projects[i].Name == name {
+			return &tracker.Projects[i]
+		}
+	}
+	return nil
+}
+
 func main() {
-	dataPath, logPath, err := getAppPaths()
+	globals, args := extractGlobalFlags(os.Args)
+	// help, version, bench and gen need no profile, config or data
+	if len(args) > 1 && args[1] == "help" {
+		fmt.Println(helpText)
+		return
+	}
+	if len(args) > 1 && args[1] == "version" {
+		cmdVersion()
+		return
+	}
+	if len(args) > 1 && args[1] == "bench" {
+		cmdBench(args[2:])
+		return
+	}
+	if len(args) > 1 && args[1] == "gen" {
+		cmdGen(args[2:], time.Now().UTC())
+		return
+	}
+	appDir, err := getAppDir()
 	if err != nil {
 		fmt.Println("Error resolving paths:", err)
 		return
 	}
-	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	profile := currentProfile(appDir, globals.profile)
+	if (len(args) < 2 || args[1] != "profile") && (!validProfileName(profile) || !profileExists(appDir, profile)) {
+		fmt.Printf("Profile '%s' not found. Use 'profile create'.\n", profile)
+		return
+	}
+	dataPath, logPath, configPath := getAppPaths(appDir, profile)
+	config, err = loadConfig(configPath)
 	if err != nil {
-		fmt.Println("Error opening log file:", err)
+		fmt.Println("Error reading config:", err)
 		return
 	}
+	readOnly = globals.readOnly || config.ReadOnly
+	if globals.clock != "" {
+		config.Clock = globals.clock
+	}
+	logFile := &lazyFile{path: logPath}
 	defer logFile.Close()
 	log.SetOutput(logFile)
 
-	args := os.Args
 	now := time.Now().UTC()
-	log.Println("Invoked:", args)
 
 	if len(args) < 2 {
 		fmt.Println("No command provided. Use 'help'.")
 		return
 	}
+	if readOnly && mutates(args) {
+		fmt.Printf("Read-only mode: '%s' is disabled.\n", args[1])
+		os.Exit(1)
+	}
 
-	tracker, err := loadTracker(dataPath)
+	if mutates(args) {
+		if err := logFile.open(); err != nil {
+			fmt.Println("Error opening log file:", err)
+			return
+		}
+		log.Println("Invoked:", os.Args)
+		storage, err := openStorage(dataPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		release, err := holdLock(storage)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer release()
+	}
+	load := loadTracker
+	if summaryCommands[args[1]] {
+		load = loadSummary
+	}
+	if ipcCommands[args[1]] {
+		load = loadViaDaemon
+	}
+	tracker, err := load(dataPath)
+	var corrupt *corruptError
+	if errors.As(err, &corrupt) && !readOnly {
+		if args[1] == "restore" {
+			// restore replaces the corrupted data itself
+			fmt.Println(err)
+			tracker, err = &TrackerData{}, nil
+		} else {
+			tracker, err = restoreLatestBackup(dataPath, err)
+		}
+	}
 	if err != nil {
+		fmt.Println(err)
 		log.Fatal(err)
 	}
+	if mutates(args) && !readOnly {
+		repairDangling(dataPath, tracker, config.Dangling, now)
+	}
 
 	switch args[1] {
-	case "help":
-		fmt.Println(helpText)
+	case "schema":
+		cmdSchema(args[2:])
 
 	case "create":
 		if len(args) < 3 {
@@ -145,157 +569,202 @@ func main() {
 		fmt.Printf("Project '%s' created.\n", name)
 
 	case "delete":
-		if len(args) < 3 {
+		rest, force := cutForce(args[2:])
+		rest, yes := cutYes(rest)
+		if len(rest) < 1 {
 			fmt.Println("Project name required.\n", helpText)
 			return
 		}
-		name := args[2]
+		name := rest[0]
+		if p := lookupProject(tracker, name); p != nil {
+			name = p.Name
+		}
 		for i, p := range tracker.Projects {
 			if p.Name == name {
-				fmt.Printf("Delete '%s'? [y/N]: ", name)
-				var r string
-				fmt.Scanln(&r)
-				if r != "y" && r != "Y" {
-					fmt.Println("Cancelled.")
+				for _, e := range p.Logs {
+					if !checkLocked(tracker, name, e, force) {
+						return
+					}
+				}
+				fmt.Printf("'%s' has %d entries (%s)", name, len(p.Logs), formatHM(projectTotal(p, now)))
+				if len(p.Logs) > 0 {
+					fmt.Printf(" from %s to %s", formatDate(p.Logs[0].Start), formatDate(lastActive(p, now)))
+				}
+				if len(p.Expenses) > 0 {
+					fmt.Printf(" and %d expenses", len(p.Expenses))
+				}
+				fmt.Println(".")
+				if !confirm(fmt.Sprintf("Delete '%s'?", name), yes) {
+					return
+				}
+				snap, ok := takeSnapshot(dataPath, "delete")
+				if !ok {
 					return
 				}
 				tracker.Projects = append(tracker.Projects[:i], tracker.Projects[i+1:]...)
 				saveTracker(dataPath, tracker)
 				fmt.Printf("Deleted '%s'.\n", name)
+				printRestoreHint(snap)
 				return
 			}
 		}
-		fmt.Printf("'%s' not found.\n", name)
+		fmt.Println(notFoundError(name))
 
 	case "start":
-		if len(args) < 3 {
-			fmt.Println("Project name required.\n", helpText)
-			return
-		}
-		name := args[2]
-		for i, p := range tracker.Projects {
-			if p.Name == name {
-				logs := p.Logs
-				if len(logs) > 0 && logs[len(logs)-1].End.IsZero() {
-					fmt.Println("Already active.")
-					return
-				}
-				tracker.Projects[i].Logs = append(logs, LogEntry{Start: now})
-				saveTracker(dataPath, tracker)
-				fmt.Printf("Started '%s' at %s\n", name, now.Format(time.RFC822))
-				return
-			}
-		}
-		fmt.Printf("'%s' not found.\n", name)
+		cmdStart(dataPath, tracker, args[2:], now)
 
 	case "stop":
-		if len(args) < 3 {
-			fmt.Println("Project name required.\n", helpText)
-			return
-		}
-		name := args[2]
-		for i, p := range tracker.Projects {
-			if p.Name == name {
-				logs := p.Logs
-				if len(logs) == 0 || !logs[len(logs)-1].End.IsZero() {
-					fmt.Println("Not active.")
-					return
-				}
-				end := now
-				dur := end.Sub(logs[len(logs)-1].Start)
-				tracker.Projects[i].Logs[len(logs)-1].End = end
-				tracker.Projects[i].TotalTime += dur
-				saveTracker(dataPath, tracker)
-				fmt.Printf("Stopped '%s': %.2fmin (Total: %.2fmin)\n", name, dur.Minutes(), tracker.Projects[i].TotalTime.Minutes())
-				return
-			}
-		}
-		fmt.Printf("'%s' not found.\n", name)
+		cmdStop(dataPath, tracker, args[2:], now)
+
+	case "archive":
+		cmdArchive(dataPath, tracker, args[2:])
+
+	case "add":
+		cmdAdd(dataPath, tracker, args[2:], now)
+
+	case "edit":
+		cmdEdit(dataPath, tracker, args[2:], now)
+
+	case "bulk":
+		cmdBulk(dataPath, tracker, args[2:], now)
+
+	case "adjust":
+		cmdAdjust(dataPath, tracker, args[2:], now)
+
+	case "doctor":
+		cmdDoctor(tracker, args[2:], now)
+
+	case "import":
+		cmdImport(dataPath, tracker, args[2:], now)
+
+	case "server":
+		cmdServer(args[2:])
+
+	case "invoice":
+		cmdInvoice(dataPath, tracker, args[2:], now)
+
+	case "invoices":
+		cmdInvoices(dataPath, tracker, args[2:], now)
+
+	case "expense":
+		cmdExpense(dataPath, tracker, args[2:], now)
+
+	case "export":
+		cmdExport(tracker, args[2:], now)
+
+	case "push":
+		cmdPush(dataPath, tracker, args[2:], now)
+
+	case "dedupe":
+		cmdDedupe(dataPath, tracker, args[2:], now)
+
+	case "fill":
+		cmdFill(dataPath, tracker, args[2:], now)
+
+	case "apply-recurring":
+		cmdApplyRecurring(dataPath, tracker, args[2:], now)
+
+	case "timeline":
+		cmdTimeline(tracker, args[2:], now)
+
+	case "journal":
+		cmdJournal(tracker, args[2:], now)
+
+	case "digest":
+		cmdDigest(dataPath, tracker, args[2:], now)
+
+	case "lock":
+		cmdLock(dataPath, tracker, args[2:], now)
+
+	case "sync":
+		cmdSync(dataPath, tracker, args[2:])
+
+	case "restore":
+		cmdRestore(dataPath, tracker, args[2:])
+
+	case "compact":
+		cmdCompact(dataPath, tracker, args[2:], now)
+
+	case "today":
+		cmdToday(dataPath, tracker, now)
+
+	case "convert":
+		cmdConvert(dataPath, tracker, args[2:])
+
+	case "profile":
+		cmdProfile(appDir, profile, args[2:])
+
+	case "serve":
+		cmdServe(appDir, dataPath, args[2:])
+
+	case "listen":
+		cmdListen(appDir, dataPath, args[2:])
+
+	case "token":
+		cmdToken(appDir, args[2:])
+
+	case "shortcuts":
+		cmdShortcuts(tracker)
+
+	case "mail":
+		cmdMail(dataPath, tracker, args[2:], now)
+
+	case "bot":
+		cmdBot(dataPath, args[2:])
+
+	case "daemon":
+		cmdDaemon(logPath, profile, args[2:])
+
+	case "pause":
+		cmdPause(dataPath, tracker, args[2:], now)
+
+	case "resume":
+		cmdResume(dataPath, tracker, args[2:], now)
+
+	case "break":
+		cmdBreak(dataPath, tracker, args[2:], now)
 
 	case "list":
-		fmt.Println("Projects:")
-		for _, p := range tracker.Projects {
-			fmt.Println("- ", p.Name)
-		}
+		cmdList(tracker, args[2:], now)
 
 	case "status":
-		fmt.Println("Active Sessions:")
-		count := 0
-		for _, p := range tracker.Projects {
-			if len(p.Logs) > 0 && p.Logs[len(p.Logs)-1].End.IsZero() {
-				start := p.Logs[len(p.Logs)-1].Start
-				dur := time.Since(start)
-				fmt.Printf("* %-10s | Started: %s | Elapsed: %.2fmin\n", p.Name, start.Format("15:04:05"), dur.Minutes())
-				count++
-			}
-		}
-		if count == 0 {
-			fmt.Println("None")
+		if code := cmdStatus(tracker, args[2:], now); code != 0 {
+			os.Exit(code)
 		}
 
-	case "stats":
-		if len(args) < 3 {
-			fmt.Println("Project name required.\n", helpText)
-			return
+	case "current":
+		if code := cmdCurrent(tracker, args[2:], now); code != 0 {
+			os.Exit(code)
 		}
-		name := args[2]
-		for _, p := range tracker.Projects {
-			if p.Name == name {
-				fmt.Println("===============================================")
-				fmt.Printf("Stats for %s:\n", name)
-				fmt.Println("===============================================")
-				fmt.Printf("Total Sessions: %d | Total Time: %.2fmin\n", len(p.Logs), p.TotalTime.Minutes())
-				if len(p.Logs) > 0 {
-					fmt.Println("# | Start               | End                 | Duration(min)")
-					fmt.Println("---|---------------------|---------------------|-------------")
-					for i, e := range p.Logs {
-						start := e.Start.Format("2006-01-02 15:04:05")
-						end := "-"
-						dur := time.Since(e.Start)
-						if !e.End.IsZero() {
-							end = e.End.Format("2006-01-02 15:04:05")
-							dur = e.End.Sub(e.Start)
-						}
-						fmt.Printf("%-3d| %-20s| %-20s| %6.2f\n", i+1, start, end, dur.Minutes())
-					}
-				}
-				return
-			}
+
+	case "elapsed":
+		if code := cmdElapsed(tracker, args[2:], now); code != 0 {
+			os.Exit(code)
 		}
-		fmt.Printf("'%s' not found.\n", name)
+
+	case "prompt":
+		cmdPrompt(tracker, args[2:], now)
+
+	case "toggle":
+		cmdToggle(dataPath, tracker, args[2:], now)
+
+	case "stats":
+		cmdStats(tracker, args[2:], now)
 
 	case "report":
-		if len(tracker.Projects) == 0 {
-			fmt.Println("No projects.")
-			return
-		}
-		// compute grand total
-		var totalAll time.Duration
-		for _, p := range tracker.Projects {
-			t := p.TotalTime
-			if len(p.Logs) > 0 && p.Logs[len(p.Logs)-1].End.IsZero() {
-				t += time.Since(p.Logs[len(p.Logs)-1].Start)
-			}
-			totalAll += t
-		}
-		fmt.Println("===================================================================")
-		fmt.Println("Summary Report: All Projects")
-		fmt.Println("===================================================================")
-		fmt.Printf("%-16s | %-8s | %-10s | %-8s\n", "Project", "Sessions", "Time(min)", "Percent")
-		fmt.Println("-----------------|----------|------------|--------")
-		for _, p := range tracker.Projects {
-			t := p.TotalTime
-			if len(p.Logs) > 0 && p.Logs[len(p.Logs)-1].End.IsZero() {
-				t += time.Since(p.Logs[len(p.Logs)-1].Start)
-			}
-			percent := 0.0
-			if totalAll > 0 {
-				percent = (t.Minutes() / totalAll.Minutes()) * 100
-			}
-			fmt.Printf("%-16s | %-8d | %-10.2f | %6.2f%%\n", p.Name, len(p.Logs), t.Minutes(), percent)
-		}
-		fmt.Println("-------------------------------------------------------------------")
-		fmt.Printf("Total time tracked: %.2f minutes\n", totalAll.Minutes())
+		cmdReport(dataPath, tracker, args[2:])
+
+	case "annotate":
+		cmdAnnotate(dataPath, tracker, args[2:])
+
+	case "amend":
+		cmdAmend(dataPath, tracker, args[2:])
+
+	case "search":
+		cmdSearch(tracker, args[2:], now)
+
+	case "set":
+		cmdSet(dataPath, tracker, args[2:])
 
 	default:
 		fmt.Println("Unknown command. Use 'help'.")
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// metaFlag collects repeated --meta key=value flags.
+type metaFlag map[string]string
+
+func (m metaFlag) String() string {
+	var parts []string
+	for k, v := range m {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (m metaFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	m[k] = v
+	return nil
+}
+
+func (p Project) matchesMeta(filter map[string]string) bool {
+	for k, v := range filter {
+		if p.Meta[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Project) sortedMetaKeys() []string {
+	keys := make([]string, 0, len(p.Meta))
+	for k := range p.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// MQTTConfig is the broker that session start/stop events are published
+// to. Broker is host:port, optionally prefixed with mqtt:// or mqtts://
+// for TLS.
+type MQTTConfig struct {
+	Broker   string `json:"broker"`
+	Topic    string `json:"topic,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// HomeAssistant also publishes Home Assistant discovery configs and
+	// sensor state under DiscoveryPrefix (default "homeassistant").
+	HomeAssistant   bool   `json:"home_assistant,omitempty"`
+	DiscoveryPrefix string `json:"discovery_prefix,omitempty"`
+}
+
+func (c MQTTConfig) topic() string {
+	if c.Topic == "" {
+		return "ptracker"
+	}
+	return strings.TrimSuffix(c.Topic, "/")
+}
+
+type mqttMessage struct {
+	topic   string
+	payload []byte
+	retain  bool
+}
+
+// mqttString encodes s as an MQTT length-prefixed string.
+func mqttString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
+
+// mqttPacket frames body with the fixed header and its variable-length
+// remaining length.
+func mqttPacket(header byte, body []byte) []byte {
+	out := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// mqttPublish connects to the broker, publishes msgs at QoS 0 and
+// disconnects. It speaks just enough MQTT 3.1.1 for that.
+func mqttPublish(cfg MQTTConfig, msgs []mqttMessage) error {
+	addr, useTLS := cfg.Broker, false
+	if rest, ok := strings.CutPrefix(addr, "mqtts://"); ok {
+		addr, useTLS = rest, true
+	} else {
+		addr = strings.TrimPrefix(addr, "mqtt://")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		if useTLS {
+			addr = net.JoinHostPort(addr, "8883")
+		} else {
+			addr = net.JoinHostPort(addr, "1883")
+		}
+	}
+	dialer := &net.Dialer{Timeout: 3 * time.Second}
+	var conn net.Conn
+	var err error
+	if useTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, nil)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	clientID := cfg.ClientID
+	if clientID == "" {
+		host, _ := os.Hostname()
+		clientID = fmt.Sprintf("ptracker-%s-%d", host, os.Getpid())
+	}
+	flags := byte(0x02) // clean session
+	body := append(mqttString("MQTT"), 4, 0, 0, 60)
+	payload := mqttString(clientID)
+	if cfg.Username != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(cfg.Username)...)
+		if cfg.Password != "" {
+			flags |= 0x40
+			payload = append(payload, mqttString(cfg.Password)...)
+		}
+	}
+	body[7] = flags
+	if _, err := conn.Write(mqttPacket(0x10, append(body, payload...))); err != nil {
+		return err
+	}
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(bufio.NewReader(conn), ack); err != nil {
+		return fmt.Errorf("mqtt: reading CONNACK: %w", err)
+	}
+	if ack[0] != 0x20 {
+		return errors.New("mqtt: unexpected reply to CONNECT")
+	}
+	if ack[3] != 0 {
+		return fmt.Errorf("mqtt: connection refused (code %d)", ack[3])
+	}
+	for _, m := range msgs {
+		header := byte(0x30)
+		if m.retain {
+			header |= 0x01
+		}
+		if _, err := conn.Write(mqttPacket(header, append(mqttString(m.topic), m.payload...))); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Write([]byte{0xE0, 0})
+	return err
+}
+
+// publishSessionEvents sends an event per start and stop to TOPIC/event
+// and, if anything changed, the retained current status to TOPIC/status.
+// With Home Assistant enabled the sensors are updated on every save.
+func publishSessionEvents(events []sessionEvent, after *TrackerData, now time.Time) {
+	topic := config.MQTT.topic()
+	var msgs []mqttMessage
+	for _, ev := range events {
+		data, _ := json.Marshal(ev)
+		msgs = append(msgs, mqttMessage{topic: topic + "/event", payload: data})
+	}
+	if len(events) > 0 {
+		data, _ := json.Marshal(buildStatus(after, now))
+		msgs = append(msgs, mqttMessage{topic: topic + "/status", payload: data, retain: true})
+	}
+	if config.MQTT.HomeAssistant {
+		msgs = append(msgs, haMessages(after, now)...)
+	}
+	if len(msgs) == 0 {
+		return
+	}
+	if err := mqttPublish(config.MQTT, msgs); err != nil {
+		log.Println("mqtt:", err)
+	}
+}
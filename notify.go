@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// notify shows a desktop notification with notify-send on Linux or
+// osascript on macOS, or an Android one under Termux.
+func notify(title, body string) error {
+	if isTermux() {
+		return termuxNotify(title, body)
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return exec.Command("notify-send", "--app-name=ptracker", title, body).Run()
+	case "darwin":
+		script := "display notification " + strconv.Quote(body) + " with title " + strconv.Quote(title)
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	return fmt.Errorf("notifications are not supported on %s", runtime.GOOS)
+}
+
+// playSound plays an audio file with the first player found.
+func playSound(file string) error {
+	if path, err := exec.LookPath("termux-media-player"); err == nil && isTermux() {
+		return exec.Command(path, "play", file).Run()
+	}
+	for _, player := range []string{"afplay", "paplay", "aplay"} {
+		if path, err := exec.LookPath(player); err == nil {
+			return exec.Command(path, file).Run()
+		}
+	}
+	return fmt.Errorf("no audio player found to play %s", file)
+}
+
+// alert announces an event of the given kind (e.g. "timebox") the ways
+// listed for it in config: any of "notify" (the default), "bell" to ring
+// the terminal bell, "sound:FILE" to play a file, "telegram" to message
+// the bot's chat, or "none".
+func alert(kind, title, body string) {
+	methods, ok := config.Alerts[kind]
+	if !ok {
+		methods = "notify"
+	}
+	for _, m := range strings.Split(methods, ",") {
+		var err error
+		switch m = strings.TrimSpace(m); {
+		case m == "notify":
+			err = notify(title, body)
+		case m == "telegram":
+			err = telegramAlert(title, body)
+		case m == "bell":
+			_, err = os.Stdout.WriteString("\a")
+		case strings.HasPrefix(m, "sound:"):
+			err = playSound(strings.TrimPrefix(m, "sound:"))
+		case m == "none", m == "":
+		default:
+			err = fmt.Errorf("unknown alert %q", m)
+		}
+		if err != nil {
+			log.Printf("%s: alert: %v", kind, err)
+		}
+	}
+}
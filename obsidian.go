@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exportObsidian writes one Markdown note per project or per day, with
+// totals in YAML frontmatter for Dataview queries and a table of the
+// sessions below it.
+func exportObsidian(tracker *TrackerData, opts exportOptions, by string) error {
+	if opts.out == "" {
+		return fmt.Errorf("-o DIR required")
+	}
+	if err := os.MkdirAll(opts.out, 0755); err != nil {
+		return err
+	}
+	var notes map[string]string
+	switch by {
+	case "project":
+		notes = obsidianByProject(tracker, opts)
+	case "day":
+		notes = obsidianByDay(tracker, opts)
+	default:
+		return fmt.Errorf("--by must be project or day")
+	}
+	for name, body := range notes {
+		if err := os.WriteFile(filepath.Join(opts.out, name+".md"), []byte(body), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Wrote %d notes to %s.\n", len(notes), opts.out)
+	return nil
+}
+
+// yamlString quotes s; a JSON string is a valid YAML scalar.
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// noteName makes s safe to use as a file name on any platform.
+func noteName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, s)
+}
+
+func sessionTable(b *strings.Builder, rows [][]string) {
+	b.WriteString("| Date | Start | End | Hours | Note |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, r := range rows {
+		fmt.Fprintf(b, "| %s |\n", strings.Join(r, " | "))
+	}
+}
+
+func sessionRow(e LogEntry, now time.Time) []string {
+	end := "running"
+	if !e.End.IsZero() {
+		end = e.End.Format("15:04")
+	}
+	return []string{e.Start.Format(dateLayout), e.Start.Format("15:04"), end, fmt.Sprintf("%.2f", e.Duration(now).Hours()), strings.ReplaceAll(e.Note, "|", `\|`)}
+}
+
+func obsidianByProject(tracker *TrackerData, opts exportOptions) map[string]string {
+	notes := map[string]string{}
+	for _, p := range opts.selectedProjects(tracker) {
+		entries := opts.entries(p)
+		var total time.Duration
+		var rows [][]string
+		for _, e := range entries {
+			total += e.Duration(opts.now)
+			rows = append(rows, sessionRow(e, opts.now))
+		}
+		var b strings.Builder
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "project: %s\n", yamlString(p.Name))
+		fmt.Fprintf(&b, "total_hours: %.2f\n", total.Hours())
+		fmt.Fprintf(&b, "sessions: %d\n", len(entries))
+		if len(entries) > 0 {
+			fmt.Fprintf(&b, "first: %s\n", entries[0].Start.Format(dateLayout))
+			fmt.Fprintf(&b, "last: %s\n", entries[len(entries)-1].Start.Format(dateLayout))
+		}
+		if p.Rate > 0 {
+			fmt.Fprintf(&b, "rate: %g\n", p.Rate)
+			fmt.Fprintf(&b, "earned: %.2f\n", earnings(total, p.Rate))
+		}
+		if p.Description != "" {
+			fmt.Fprintf(&b, "description: %s\n", yamlString(p.Description))
+		}
+		for _, k := range p.sortedMetaKeys() {
+			fmt.Fprintf(&b, "%s: %s\n", yamlString(k), yamlString(p.Meta[k]))
+		}
+		b.WriteString("tags: [ptracker]\n---\n\n")
+		fmt.Fprintf(&b, "# %s\n\n", p.Name)
+		sessionTable(&b, rows)
+		notes[noteName(p.Name)] = b.String()
+	}
+	return notes
+}
+
+func obsidianByDay(tracker *TrackerData, opts exportOptions) map[string]string {
+	type dayEntry struct {
+		project string
+		e       LogEntry
+	}
+	days := map[string][]dayEntry{}
+	for _, p := range opts.selectedProjects(tracker) {
+		for _, e := range opts.entries(p) {
+			day := e.Start.Format(dateLayout)
+			days[day] = append(days[day], dayEntry{p.Name, e})
+		}
+	}
+	notes := map[string]string{}
+	for day, entries := range days {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].e.Start.Before(entries[j].e.Start) })
+		perProject := map[string]time.Duration{}
+		var total time.Duration
+		var b strings.Builder
+		var rows [][]string
+		for _, de := range entries {
+			d := de.e.Duration(opts.now)
+			perProject[de.project] += d
+			total += d
+			rows = append(rows, append([]string{de.project}, sessionRow(de.e, opts.now)[1:]...))
+		}
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "date: %s\n", day)
+		fmt.Fprintf(&b, "total_hours: %.2f\n", total.Hours())
+		fmt.Fprintf(&b, "sessions: %d\n", len(entries))
+		b.WriteString("projects:\n")
+		var names []string
+		for name := range perProject {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %s: %.2f\n", yamlString(name), perProject[name].Hours())
+		}
+		b.WriteString("tags: [ptracker]\n---\n\n")
+		fmt.Fprintf(&b, "# %s\n\n", day)
+		b.WriteString("| Project | Start | End | Hours | Note |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, r := range rows {
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(r, " | "))
+		}
+		notes[day] = b.String()
+	}
+	return notes
+}
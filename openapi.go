@@ -0,0 +1,141 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// openAPIDocument describes apiRoutes as an OpenAPI 3 document. Schemas
+// are derived from the request and response models by reflection, using
+// their json tags; fields with omitempty or omitzero are optional.
+func openAPIDocument() map[string]any {
+	schemas := map[string]any{}
+	base := "#/components/schemas/"
+	paths := map[string]map[string]any{}
+	errRef := schemaFor(reflect.TypeOf(errorResponse{}), schemas, base)
+	for _, rt := range apiRoutes {
+		op := map[string]any{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     jsonContent(schemaFor(reflect.TypeOf(rt.Response), schemas, base)),
+				},
+				"default": map[string]any{
+					"description": "Error",
+					"content":     jsonContent(errRef),
+				},
+			},
+		}
+		if rt.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaFor(reflect.TypeOf(rt.Request), schemas, base)),
+			}
+		}
+		var params []any
+		for _, q := range rt.Query {
+			params = append(params, map[string]any{
+				"name":        q.Name,
+				"in":          "query",
+				"description": q.Description,
+				"schema":      map[string]any{"type": q.Type},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if paths[rt.Path] == nil {
+			paths[rt.Path] = map[string]any{}
+		}
+		paths[rt.Path][strings.ToLower(rt.Method)] = op
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "ptracker",
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []any{map[string]any{"bearer": []any{}}},
+	}
+}
+
+// operationID turns "POST /v1/start" into "postV1Start".
+func operationID(rt apiRoute) string {
+	id := strings.ToLower(rt.Method)
+	for _, part := range strings.Split(rt.Path, "/") {
+		if part != "" {
+			id += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return id
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema for t, adding named structs to schemas and
+// referring to them by $ref relative to base.
+func schemaFor(t reflect.Type, schemas map[string]any, base string) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return schemaFor(t.Elem(), schemas, base)
+	case t.Kind() == reflect.Struct:
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		ref := map[string]any{"$ref": base + name}
+		if _, ok := schemas[name]; ok {
+			return ref
+		}
+		schemas[name] = nil // placeholder so recursive types terminate
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if !f.IsExported() || tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = schemaFor(f.Type, schemas, base)
+			if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+				required = append(required, name)
+			}
+		}
+		s := map[string]any{"type": "object", "properties": props}
+		if required != nil {
+			s["required"] = required
+		}
+		schemas[name] = s
+		return ref
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas, base)}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas, base)}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
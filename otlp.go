@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLPConfig is the OpenTelemetry collector that completed sessions are
+// sent to as spans, over OTLP/HTTP with JSON encoding.
+type OTLPConfig struct {
+	// Endpoint is the collector base URL, e.g. http://localhost:4318;
+	// spans are posted to Endpoint/v1/traces.
+	Endpoint string            `json:"endpoint"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+type otlpValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+}
+
+type otlpValues struct {
+	Values []otlpValue `json:"values"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+func otlpString(key, v string) otlpAttribute {
+	return otlpAttribute{key, otlpValue{StringValue: &v}}
+}
+
+func otlpNanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// sessionSpan turns a closed entry into a span. The span ID is the entry
+// ID, so a collector can recognize a session sent twice.
+func sessionSpan(e LogEntry) otlpSpan {
+	sum := sha256.Sum256([]byte(e.ID))
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(sum[:16]),
+		SpanID:            e.ID,
+		Name:              "session",
+		Kind:              1, // internal
+		StartTimeUnixNano: otlpNanos(e.Start),
+		EndTimeUnixNano:   otlpNanos(e.End),
+	}
+	if len(span.SpanID) != 16 {
+		span.SpanID = hex.EncodeToString(sum[16:24])
+	}
+	if e.Note != "" {
+		span.Name = e.Note
+		span.Attributes = append(span.Attributes, otlpString("ptracker.note", e.Note))
+	}
+	if e.User != "" {
+		span.Attributes = append(span.Attributes, otlpString("ptracker.user", e.User))
+	}
+	if len(e.Tags) > 0 {
+		var tags otlpValues
+		for _, t := range e.Tags {
+			tags.Values = append(tags.Values, otlpValue{StringValue: &t})
+		}
+		span.Attributes = append(span.Attributes, otlpAttribute{"ptracker.tags", otlpValue{ArrayValue: &tags}})
+	}
+	billable := e.isBillable()
+	span.Attributes = append(span.Attributes, otlpAttribute{"ptracker.billable", otlpValue{BoolValue: &billable}})
+	return span
+}
+
+// exportSpans posts closed entries, grouped by project, to the collector.
+// Each project is its own resource so it shows up as a service.
+func exportSpans(cfg OTLPConfig, sessions map[string][]LogEntry) error {
+	var req struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	for project, entries := range sessions {
+		var rs otlpResourceSpans
+		rs.Resource.Attributes = []otlpAttribute{
+			otlpString("service.name", project),
+			otlpString("ptracker.project", project),
+		}
+		var ss otlpScopeSpans
+		ss.Scope.Name, ss.Scope.Version = "ptracker", version
+		for _, e := range entries {
+			ss.Spans = append(ss.Spans, sessionSpan(e))
+		}
+		rs.ScopeSpans = []otlpScopeSpans{ss}
+		req.ResourceSpans = append(req.ResourceSpans, rs)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hr, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	for k, v := range cfg.Headers {
+		hr.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(hr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
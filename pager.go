@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// autoPager decides whether to page: always with --pager, never with
+// --no-pager, and otherwise when stdout is a terminal. less -F then only
+// keeps the pager open for output taller than the screen.
+func autoPager(force, off bool) bool {
+	return force || !off && isTerminal(os.Stdout)
+}
+
+// withPager runs fn with a writer that feeds $PAGER (default "less -FRX")
+// when enabled, falling back to stdout if the pager can't be started. LESS
+// defaults to FRX, like git, so a plain PAGER=less behaves the same.
+func withPager(enabled bool, fn func(w io.Writer)) {
+	if !enabled {
+		fn(os.Stdout)
+		return
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -FRX"
+	}
+	parts := strings.Fields(pager)
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		fn(os.Stdout)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		fn(os.Stdout)
+		return
+	}
+	fn(in)
+	in.Close()
+	cmd.Wait()
+}
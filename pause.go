@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Pause struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the time tracked by the entry up to now, excluding
+// pauses. Open entries and open pauses are measured until now.
+func (e LogEntry) Duration(now time.Time) time.Duration {
+	end := e.End
+	if end.IsZero() {
+		end = now
+	}
+	d := end.Sub(e.Start)
+	for _, p := range e.Pauses {
+		pend := p.End
+		if pend.IsZero() {
+			pend = end
+		}
+		d -= pend.Sub(p.Start)
+	}
+	return d
+}
+
+func (e LogEntry) paused() bool {
+	return len(e.Pauses) > 0 && e.Pauses[len(e.Pauses)-1].End.IsZero()
+}
+
+func cmdPause(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	if len(args) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, args[0])
+	if p == nil {
+		fmt.Println(notFoundError(args[0]))
+		return
+	}
+	e := p.running()
+	if e == nil {
+		fmt.Println("Not active.")
+		return
+	}
+	if e.paused() {
+		fmt.Println("Already paused.")
+		return
+	}
+	e.Pauses = append(e.Pauses, Pause{Start: now})
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Paused '%s' at %s\n", p.Name, now.Format(time.RFC822))
+}
+
+func cmdResume(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	if len(args) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, args[0])
+	if p == nil {
+		fmt.Println(notFoundError(args[0]))
+		return
+	}
+	if len(p.Logs) == 0 || !p.Logs[len(p.Logs)-1].paused() {
+		fmt.Println("Not paused.")
+		return
+	}
+	e := &p.Logs[len(p.Logs)-1]
+	pause := &e.Pauses[len(e.Pauses)-1]
+	pause.End = now
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Resumed '%s' after %.2fmin\n", p.Name, pause.End.Sub(pause.Start).Minutes())
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirPath is where config and data live: $PTRACKER_DIR if set, else
+// ~/.ptracker, or on Windows %APPDATA%\ptracker unless an existing
+// ~/.ptracker is found.
+func appDirPath() (string, error) {
+	home, err := os.UserHomeDir()
+	return resolveAppDir(runtime.GOOS, os.Getenv, home, err)
+}
+
+// resolveAppDir applies appDirPath's rules for goos, so the Windows ones
+// can be tested anywhere.
+func resolveAppDir(goos string, getenv func(string) string, home string, homeErr error) (string, error) {
+	if dir := getenv("PTRACKER_DIR"); dir != "" {
+		return dir, nil
+	}
+	if goos == "windows" {
+		legacy := filepath.Join(home, ".ptracker")
+		if _, serr := os.Stat(legacy); homeErr == nil && serr == nil {
+			return legacy, nil
+		}
+		if appData := getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "ptracker"), nil
+		}
+	}
+	if homeErr != nil {
+		return "", homeErr
+	}
+	return filepath.Join(home, ".ptracker"), nil
+}
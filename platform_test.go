@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveAppDir(t *testing.T) {
+	withLegacy := t.TempDir()
+	if err := os.Mkdir(filepath.Join(withLegacy, ".ptracker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fresh := t.TempDir()
+	noHome := errors.New("no home directory")
+	tests := []struct {
+		name    string
+		goos    string
+		env     map[string]string
+		home    string
+		homeErr error
+		want    string
+		wantErr bool
+	}{
+		{"override", "linux", map[string]string{"PTRACKER_DIR": "/data/pt"}, fresh, nil, "/data/pt", false},
+		{"override on windows", "windows", map[string]string{"PTRACKER_DIR": "/data/pt", "APPDATA": "/appdata"}, withLegacy, nil, "/data/pt", false},
+		{"unix home", "linux", nil, fresh, nil, filepath.Join(fresh, ".ptracker"), false},
+		{"unix ignores APPDATA", "darwin", map[string]string{"APPDATA": "/appdata"}, fresh, nil, filepath.Join(fresh, ".ptracker"), false},
+		{"unix without home", "linux", nil, "", noHome, "", true},
+		{"windows APPDATA", "windows", map[string]string{"APPDATA": "/appdata"}, fresh, nil, filepath.Join("/appdata", "ptracker"), false},
+		{"windows keeps legacy dir", "windows", map[string]string{"APPDATA": "/appdata"}, withLegacy, nil, filepath.Join(withLegacy, ".ptracker"), false},
+		{"windows APPDATA without home", "windows", map[string]string{"APPDATA": "/appdata"}, "", noHome, filepath.Join("/appdata", "ptracker"), false},
+		{"windows without APPDATA", "windows", nil, fresh, nil, filepath.Join(fresh, ".ptracker"), false},
+		{"windows without either", "windows", nil, "", noHome, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			got, err := resolveAppDir(tt.goos, getenv, tt.home, tt.homeErr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
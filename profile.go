@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultProfile = "default"
+
+// profileDir returns the directory holding a profile's data. The default
+// profile lives directly in the app dir so existing installs keep working.
+func profileDir(appDir, name string) string {
+	if name == defaultProfile {
+		return appDir
+	}
+	return filepath.Join(appDir, "profiles", name)
+}
+
+// currentProfile resolves the active profile from the --profile flag,
+// PTRACKER_PROFILE, or the profile last chosen with `profile switch`.
+func currentProfile(appDir, flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv("PTRACKER_PROFILE"); env != "" {
+		return env
+	}
+	if data, err := os.ReadFile(filepath.Join(appDir, "profile")); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	return defaultProfile
+}
+
+func validProfileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+func profileExists(appDir, name string) bool {
+	if name == defaultProfile {
+		return true
+	}
+	fi, err := os.Stat(profileDir(appDir, name))
+	return err == nil && fi.IsDir()
+}
+
+func cmdProfile(appDir, active string, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Subcommand required: list, create, switch.\n", helpText)
+		return
+	}
+	switch args[0] {
+	case "list":
+		names := []string{defaultProfile}
+		entries, _ := os.ReadDir(filepath.Join(appDir, "profiles"))
+		for _, e := range entries {
+			if e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+		fmt.Println("Profiles:")
+		for _, n := range names {
+			mark := " "
+			if n == active {
+				mark = "*"
+			}
+			fmt.Println(mark, n)
+		}
+
+	case "create":
+		if len(args) < 2 || !validProfileName(args[1]) {
+			fmt.Println("Valid profile name required.")
+			return
+		}
+		if profileExists(appDir, args[1]) {
+			fmt.Printf("Profile '%s' exists.\n", args[1])
+			return
+		}
+		if err := os.MkdirAll(profileDir(appDir, args[1]), 0755); err != nil {
+			fmt.Println("Error creating profile:", err)
+			return
+		}
+		fmt.Printf("Profile '%s' created.\n", args[1])
+
+	case "switch":
+		if len(args) < 2 {
+			fmt.Println("Profile name required.")
+			return
+		}
+		if !validProfileName(args[1]) || !profileExists(appDir, args[1]) {
+			fmt.Printf("Profile '%s' not found.\n", args[1])
+			return
+		}
+		if err := os.WriteFile(filepath.Join(appDir, "profile"), []byte(args[1]+"\n"), 0644); err != nil {
+			fmt.Println("Error switching profile:", err)
+			return
+		}
+		fmt.Printf("Switched to profile '%s'.\n", args[1])
+
+	default:
+		fmt.Println("Unknown profile command. Use list, create or switch.")
+	}
+}
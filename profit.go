@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// costRate is the internal hourly cost of an entry: the user's rate from
+// "cost_rates" in config if set, otherwise the project's cost rate.
+func costRate(p Project, e LogEntry) float64 {
+	if r, ok := config.CostRates[e.User]; ok {
+		return r
+	}
+	return p.CostRate
+}
+
+// reportProfitability compares what each project bills (billable time at
+// its rate, rounded as for invoices, plus expenses) with what the time
+// cost (all time at cost rates) over closed entries in [from, to).
+func reportProfitability(projects []Project, from, to time.Time) {
+	round, err := billingRounder(config.Rounding)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	title := "Summary Report: Profitability"
+	if !from.IsZero() || !to.IsZero() {
+		title += " (" + rangeLabel(from, to) + ")"
+	}
+	fmt.Println("===================================================================")
+	fmt.Println(title)
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %12s | %12s | %12s | %7s\n", "Project", "Revenue", "Cost", "Margin", "Margin%")
+	fmt.Println("-----------------|--------------|--------------|--------------|--------")
+	var revenueAll, costAll float64
+	missing := false
+	for _, p := range projects {
+		var billed time.Duration
+		var cost float64
+		for _, e := range p.Logs {
+			if e.End.IsZero() || !inRange(e.Start, from, to) {
+				continue
+			}
+			d := e.Duration(e.End)
+			if e.isBillable() {
+				billed += round(d)
+			}
+			r := costRate(p, e)
+			if r == 0 {
+				missing = true
+			}
+			cost += earnings(d, r)
+		}
+		revenue := earnings(billed, p.Rate) + p.expenseTotal(from, to)
+		if revenue == 0 && cost == 0 {
+			continue
+		}
+		pct := "-"
+		if revenue != 0 {
+			pct = fmt.Sprintf("%6s%%", formatDecimal((revenue-cost)/revenue*100, 1))
+		}
+		fmt.Printf("%s | %12s | %12s | %12s | %7s\n", projectLabel(p, 16), formatMoney(revenue), formatMoney(cost), formatMoney(revenue-cost), pct)
+		revenueAll += revenue
+		costAll += cost
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("Revenue: %s, cost: %s, margin: %s", formatMoney(revenueAll), formatMoney(costAll), formatMoney(revenueAll-costAll))
+	if revenueAll != 0 {
+		fmt.Printf(" (%s%%)", formatDecimal((revenueAll-costAll)/revenueAll*100, 1))
+	}
+	fmt.Println()
+	if missing {
+		fmt.Println(`Some time has no cost rate; use 'set --cost-rate' or "cost_rates" in config.`)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultPrompt = "%icon%project %elapsed"
+
+// compactDuration renders d as "7m" or "1h05m" for tight spaces.
+func compactDuration(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// promptSegment expands a prompt format for the running session:
+// %project, %elapsed, %icon (the project's icon and a space, if it has
+// one) and %% for a percent sign.
+func promptSegment(format string, p *Project, e *LogEntry, now time.Time) string {
+	icon := ""
+	if p.Icon != "" {
+		icon = p.Icon + " "
+	}
+	return strings.NewReplacer(
+		"%%", "%",
+		"%project", p.Name,
+		"%elapsed", compactDuration(e.Duration(now)),
+		"%icon", icon,
+	).Replace(format)
+}
+
+// cmdPrompt prints a segment for a shell prompt, or nothing when idle. The
+// color codes are wrapped for zsh or bash with --shell so the shell
+// doesn't count them towards the prompt's width.
+func cmdPrompt(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	format := fs.String("format", config.Prompt, "segment format with %project, %elapsed and %icon")
+	shell := fs.String("shell", "", "wrap color codes for zsh or bash")
+	noColor := fs.Bool("no-color", false, "leave out color codes")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	p, e := currentSession(tracker, "")
+	if p == nil {
+		return
+	}
+	if *format == "" {
+		*format = defaultPrompt
+	}
+	segment := promptSegment(*format, p, e, now)
+	code, ok := ansiColors[p.Color]
+	if !ok {
+		code = ansiColors["cyan"]
+	}
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		fmt.Println(segment)
+		return
+	}
+	start, end := "\033["+code+"m", "\033[0m"
+	switch *shell {
+	case "zsh":
+		start, end = "%{"+start+"%}", "%{"+end+"%}"
+		segment = strings.ReplaceAll(segment, "%", "%%")
+	case "bash":
+		// readline's markers work in $(...), unlike \[ and \]
+		start, end = "\001"+start+"\002", "\001"+end+"\002"
+	case "":
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown shell '%s'. Use zsh or bash.\n", *shell)
+		return
+	}
+	fmt.Println(start + segment + end)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const notionVersion = "2022-06-28"
+
+var notionAPI = "https://api.notion.com/v1"
+
+// notionProperties builds a database row for an entry. The database needs
+// a title property "Project", dates "Start" and "End", a number
+// "Duration" (hours) and a text property "Note".
+func notionProperties(project string, e LogEntry) map[string]any {
+	text := func(s string) []any { return []any{map[string]any{"text": map[string]any{"content": s}}} }
+	return map[string]any{
+		"Project":  map[string]any{"title": text(project)},
+		"Start":    map[string]any{"date": map[string]any{"start": e.Start.Format(time.RFC3339)}},
+		"End":      map[string]any{"date": map[string]any{"start": e.End.Format(time.RFC3339)}},
+		"Duration": map[string]any{"number": float64(e.Duration(e.End).Round(time.Minute)) / float64(time.Hour)},
+		"Note":     map[string]any{"rich_text": text(e.Note)},
+	}
+}
+
+func notionCreatePage(token, database string, props map[string]any) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"parent":     map[string]any{"database_id": database},
+		"properties": props,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, notionAPI+"/pages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Message string }
+		json.Unmarshal(data, &e)
+		return "", fmt.Errorf("notion: %s %s", resp.Status, e.Message)
+	}
+	var page struct{ ID string }
+	if err := json.Unmarshal(data, &page); err != nil {
+		return "", err
+	}
+	return page.ID, nil
+}
+
+// cmdPush sends closed entries to an external service. Each pushed entry
+// records the remote ID under Pushed, keyed by destination, so pushing
+// again only sends what's new.
+func cmdPush(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	database := fs.String("database", "", "Notion database ID (default: each project's notion.database metadata)")
+	fromStr := fs.String("from", "", "first day to push")
+	toStr := fs.String("to", "", "last day to push")
+	dryRun := fs.Bool("dry-run", false, "show what would be pushed")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 || pos[0] != "notion" {
+		fmt.Println("Usage: push notion --database ID")
+		return
+	}
+	if *database == "" && !slices.ContainsFunc(tracker.Projects, func(p Project) bool { return p.Meta[metaNotionDatabase] != "" }) {
+		fmt.Println("--database required, or set notion.database metadata on projects.")
+		return
+	}
+	token := config.NotionToken
+	if token == "" {
+		token = os.Getenv("NOTION_TOKEN")
+	}
+	if token == "" && !*dryRun {
+		fmt.Println(`Notion token required: set "notion_token" in config or NOTION_TOKEN.`)
+		return
+	}
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	pushed, failed := 0, 0
+	for pi := range tracker.Projects {
+		p := &tracker.Projects[pi]
+		database := projectSetting(p, metaNotionDatabase, *database)
+		if database == "" {
+			continue
+		}
+		key := "notion:" + strings.ReplaceAll(database, "-", "")
+		for i := range p.Logs {
+			e := &p.Logs[i]
+			if e.End.IsZero() || e.Pushed[key] != "" || !inRange(e.Start, from, to) {
+				continue
+			}
+			if *dryRun {
+				fmt.Printf("%s | %s | %.2fmin | %s\n", p.Name, e.Start.Format("2006-01-02 15:04"), e.Duration(e.End).Minutes(), e.Note)
+				pushed++
+				continue
+			}
+			id, err := notionCreatePage(token, database, notionProperties(p.Name, *e))
+			if err != nil {
+				fmt.Printf("'%s' #%d: %v\n", p.Name, i+1, err)
+				failed++
+				if failed >= 3 {
+					break
+				}
+				continue
+			}
+			if e.Pushed == nil {
+				e.Pushed = map[string]string{}
+			}
+			e.Pushed[key] = id
+			pushed++
+			// Notion allows about three requests per second
+			time.Sleep(350 * time.Millisecond)
+		}
+	}
+	if *dryRun {
+		fmt.Printf("%d entries to push.\n", pushed)
+		return
+	}
+	if pushed > 0 {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	fmt.Printf("Pushed %d entries to Notion.", pushed)
+	if failed > 0 {
+		fmt.Printf(" %d failed; run push again to retry.", failed)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+	"unicode"
+)
+
+// earnings is what d is worth at an hourly rate.
+func earnings(d time.Duration, rate float64) float64 {
+	return d.Hours() * rate
+}
+
+// formatMoney renders an amount in the configured currency: a code such
+// as "EUR" goes after the amount, a symbol such as "$" before it.
+func formatMoney(amount float64) string {
+	c := config.Currency
+	switch {
+	case c == "":
+		return formatDecimal(amount, 2)
+	case len(c) == 3 && unicode.IsLetter(rune(c[0])):
+		return formatDecimal(amount, 2) + " " + c
+	}
+	return c + formatDecimal(amount, 2)
+}
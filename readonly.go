@@ -0,0 +1,61 @@
+package main
+
+import "errors"
+
+var errReadOnly = errors.New("read-only mode: changes are disabled")
+
+// readOnly is set by --read-only or the "read_only" config setting.
+// saveTracker refuses to write while it is set.
+var readOnly bool
+
+// mutatingCommands change the data file and are rejected up front in
+// read-only mode rather than failing after doing their work.
+var mutatingCommands = map[string]bool{
+	"create":   true,
+	"delete":   true,
+	"start":    true,
+	"stop":     true,
+	"archive":  true,
+	"toggle":   true,
+	"add":      true,
+	"edit":     true,
+	"annotate": true,
+	"amend":    true,
+	"pause":    true,
+	"resume":   true,
+	"break":    true,
+	"set":      true,
+	"convert":  true,
+	"compact":  true,
+	"import":   true,
+	"sync":     true,
+	"token":    true,
+	"invoice":  true,
+	"lock":     true,
+	"push":     true,
+	"fill":     true,
+	"dedupe":   true,
+	"adjust":   true,
+	"bulk":     true,
+	"mail":     true,
+
+	"apply-recurring": true,
+}
+
+// mutatingSubcommands change the data file only for some subcommands.
+var mutatingSubcommands = map[string]map[string]bool{
+	"invoices": {"mark-paid": true},
+	"expense":  {"add": true, "rm": true},
+}
+
+// mutates reports whether the command line in args changes the data file.
+func mutates(args []string) bool {
+	if args[1] == "restore" {
+		// without a file it only lists what can be restored
+		return len(args) > 2
+	}
+	if subs, ok := mutatingSubcommands[args[1]]; ok {
+		return len(args) > 2 && subs[args[2]]
+	}
+	return mutatingCommands[args[1]]
+}
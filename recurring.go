@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const sourceRecurring = "recurring"
+
+// RecurringBlock is a time block that apply-recurring turns into an entry
+// on each matching day once it is over. Start and End are HH:MM, in the
+// same UTC clock as every other time.
+type RecurringBlock struct {
+	Name    string   `json:"name"`
+	Project string   `json:"project"`
+	Days    string   `json:"days,omitempty"`
+	Start   string   `json:"start"`
+	End     string   `json:"end"`
+	Note    string   `json:"note,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// onDay reports whether the block recurs on d. Days is "daily" (the
+// default), "weekdays", "weekends" or a list such as "mon,wed,fri".
+func (b RecurringBlock) onDay(d time.Weekday) (bool, error) {
+	weekend := d == time.Saturday || d == time.Sunday
+	switch strings.ToLower(b.Days) {
+	case "", "daily":
+		return true, nil
+	case "weekdays":
+		return !weekend, nil
+	case "weekends":
+		return weekend, nil
+	}
+	for _, s := range strings.Split(b.Days, ",") {
+		key := strings.ToLower(strings.TrimSpace(s))
+		wd, ok := weekdayNames[key[:min(3, len(key))]]
+		if !ok {
+			return false, fmt.Errorf("recurring '%s': invalid day %q", b.Name, s)
+		}
+		if wd == d {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// span returns the block's start and end on day.
+func (b RecurringBlock) span(day time.Time) (start, end time.Time, err error) {
+	if start, err = parseDateTime(day.Format(dateLayout)+" "+b.Start, day); err != nil {
+		return start, end, fmt.Errorf("recurring '%s': %w", b.Name, err)
+	}
+	if end, err = parseDateTime(day.Format(dateLayout)+" "+b.End, day); err != nil {
+		return start, end, fmt.Errorf("recurring '%s': %w", b.Name, err)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("recurring '%s': end must be after start", b.Name)
+	}
+	return start, end, nil
+}
+
+// applyRecurring adds an entry for every block that has finished on the
+// days in [from, to) and isn't already covered by an entry of its project.
+// Locked periods are left alone. It returns a line per added entry.
+func applyRecurring(tracker *TrackerData, blocks []RecurringBlock, from, to, now time.Time) ([]string, error) {
+	var added []string
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, b := range blocks {
+			ok, err := b.onDay(day.Weekday())
+			if err != nil {
+				return added, err
+			}
+			if !ok {
+				continue
+			}
+			start, end, err := b.span(day)
+			if err != nil {
+				return added, err
+			}
+			if end.After(now) {
+				continue
+			}
+			p := findProject(tracker, b.Project)
+			if p == nil {
+				return added, fmt.Errorf("recurring '%s': %w", b.Name, notFoundError(b.Project))
+			}
+			if findOverlap(p, start, end, -1, now) >= 0 || lockedAt(tracker, p.Name, LogEntry{Start: start}) != nil {
+				continue
+			}
+			insertEntry(p, LogEntry{Start: start, End: end, Note: b.Note, User: currentUser(),
+				Tags: append([]string(nil), b.Tags...), Source: sourceRecurring})
+			recomputeTotal(p)
+			added = append(added, fmt.Sprintf("%s: %s %s-%s '%s'", b.Name, day.Format(dateLayout),
+				start.Format("15:04"), end.Format("15:04"), p.Name))
+		}
+	}
+	return added, nil
+}
+
+func cmdApplyRecurring(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("apply-recurring", flag.ContinueOnError)
+	fromStr := fs.String("from", "today", "first day to fill in")
+	toStr := fs.String("to", "today", "last day to fill in")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if len(config.Recurring) == 0 {
+		fmt.Println("No recurring blocks; define them under \"recurring\" in config.")
+		return
+	}
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	added, err := applyRecurring(tracker, config.Recurring, from, to, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(added) == 0 {
+		fmt.Println("Nothing to add.")
+		return
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	for _, a := range added {
+		fmt.Println("Added", a)
+	}
+}
+
+var errNothingDue = errors.New("nothing due")
+
+// runRecurring applies recurring blocks for today every minute, and for
+// yesterday as well so blocks ending near midnight aren't missed.
+func (s *apiServer) runRecurring(blocks []RecurringBlock) {
+	for range time.Tick(time.Minute) {
+		now := time.Now().UTC()
+		today, _ := parseDate("today", now)
+		var added []string
+		err := s.store.update("apply-recurring", func(tracker *TrackerData) error {
+			var err error
+			added, err = applyRecurring(tracker, blocks, today.AddDate(0, 0, -1), today.AddDate(0, 0, 1), now)
+			if err == nil && len(added) == 0 {
+				return errNothingDue
+			}
+			return err
+		})
+		if err != nil {
+			if err != errNothingDue {
+				log.Println("recurring:", err)
+			}
+			continue
+		}
+		for _, a := range added {
+			log.Println("recurring: added", a)
+		}
+	}
+}
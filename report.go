@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func cmdReport(dataPath string, tracker *TrackerData, args []string) {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	meta := metaFlag{}
+	fs.Var(meta, "meta", "only include projects with metadata key=value (repeatable)")
+	byUser := fs.Bool("by-user", false, "total time per user instead of per project")
+	includeArchives := fs.Bool("include-archives", false, "include entries moved out by compact")
+	fromStr := fs.String("from", "", "only count time on or after this date")
+	toStr := fs.String("to", "", "only count time on or before this date")
+	groupBy := fs.String("group-by", "", "break totals down by day, week or month")
+	rounded := fs.Bool("rounded", config.Rounding.Reports, "compare billed (rounded) with actual time")
+	raw := fs.Bool("raw", false, "exact durations, even if config rounds reports")
+	profitability := fs.Bool("profitability", false, "revenue, cost and margin per project")
+	state := fs.String("state", "", "only projects in this state, or all (default: all but archived)")
+	staleStr := fs.String("stale", "", "list projects with no activity for this long, e.g. 30d")
+	trend := fs.Bool("trend", false, "hours per week with a sparkline and slope per project")
+	window := fs.String("window", "8w", "how far back --trend looks, e.g. 4w")
+	format := fs.String("format", "", "print the summary as csv or tsv")
+	month := fs.Bool("month", false, "forecast this month's totals at the current pace")
+	verify := fs.Bool("verify", false, "check the report math's invariants against the data instead of reporting")
+	compare := fs.String("compare", "", "compare this period with the one given after it, e.g. --compare this-week last-week")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	now := time.Now().UTC()
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	keep, err := stateFilter(*state)
+	if err == nil {
+		err = checkTableFormat(*format)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *groupBy != "" && !slices.Contains(groupings, *groupBy) {
+		fmt.Printf("Unknown grouping '%s'. Use %s.\n", *groupBy, strings.Join(groupings, ", "))
+		return
+	}
+	if *format != "" && (*byUser || *groupBy != "" || *rounded || *profitability || *staleStr != "" || *trend || *month || *compare != "") {
+		fmt.Println("--format only applies to the summary report.")
+		return
+	}
+	if *verify {
+		reportVerify(dataPath, now)
+		return
+	}
+	ranged := !from.IsZero() || !to.IsZero()
+	if *raw {
+		*rounded = false
+	}
+	if *byUser || *groupBy != "" || *rounded || *profitability || (ranged && *includeArchives) {
+		// these need every entry, not just the summary
+		full, err := loadTracker(dataPath)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		tracker = full
+	}
+	if *includeArchives {
+		if err := mergeArchives(dataPath, tracker); err != nil {
+			fmt.Println("Error reading archives:", err)
+			return
+		}
+	}
+	var idx *dailyIndex
+	if ranged && !*byUser {
+		if *includeArchives {
+			idx = &dailyIndex{Projects: map[string]*projectIndex{}}
+			updateIndex(idx, tracker)
+		} else if idx, err = indexFor(dataPath, tracker.Checksum); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	measure := func(p Project) (time.Duration, int) {
+		if idx != nil {
+			return rangeTotal(idx, p, from, to, now)
+		}
+		return projectTotal(p, now), p.sessionCount()
+	}
+
+	var projects []Project
+	var breaks *Project
+	for _, p := range tracker.Projects {
+		if p.Name == breakProject {
+			breaks = &p
+			continue
+		}
+		if p.matchesMeta(meta) && keep(p) {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects.")
+		return
+	}
+	if *month {
+		idx, err := indexFor(dataPath, tracker.Checksum)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		reportMonth(idx, projects, now)
+		return
+	}
+	if *trend {
+		span, err := parseSpan(*window)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		idx, err := indexFor(dataPath, tracker.Checksum)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		reportTrend(idx, projects, span, now)
+		return
+	}
+	if *compare != "" {
+		a, b, ok := strings.Cut(*compare, ",")
+		if !ok {
+			if len(pos) != 1 {
+				fmt.Println("Two periods required: --compare this-week last-week")
+				return
+			}
+			b = pos[0]
+		}
+		idx, err := indexFor(dataPath, tracker.Checksum)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		reportCompare(idx, projects, a, b, now)
+		return
+	}
+	if *staleStr != "" {
+		idle, err := parseSpan(*staleStr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		reportStale(projects, idle, now)
+		return
+	}
+	if *byUser {
+		reportByUser(projects, from, to, now)
+		return
+	}
+	if *groupBy != "" {
+		reportGrouped(projects, *groupBy, from, to, now)
+		return
+	}
+	if *profitability {
+		reportProfitability(projects, from, to)
+		return
+	}
+	if *rounded {
+		reportRounded(projects, from, to)
+		return
+	}
+	// compute grand total
+	var totalAll time.Duration
+	for _, p := range projects {
+		t, _ := measure(p)
+		totalAll += t
+	}
+	if *format != "" {
+		var rows [][]string
+		for _, p := range projects {
+			t, sessions := measure(p)
+			rows = append(rows, []string{p.Name, strconv.Itoa(sessions), minutes(t), formatDecimal(percentShare(t, totalAll), 2)})
+		}
+		writeTable(os.Stdout, *format, []string{"project", "sessions", "minutes", "percent"}, rows)
+		return
+	}
+	title := "Summary Report: All Projects"
+	if ranged {
+		title += " (" + rangeLabel(from, to) + ")"
+	}
+	fmt.Println("===================================================================")
+	fmt.Println(title)
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %-8s | %-10s | %-8s\n", "Project", "Sessions", "Time(min)", "Percent")
+	fmt.Println("-----------------|----------|------------|--------")
+	for _, p := range projects {
+		t, sessions := measure(p)
+		fmt.Printf("%s | %-8d | %-10s | %6s%%\n", projectLabel(p, 16), sessions, formatDecimal(t.Minutes(), 2), formatDecimal(percentShare(t, totalAll), 2))
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("Total time tracked: %s minutes\n", formatDecimal(totalAll.Minutes(), 2))
+	if breaks != nil {
+		t, sessions := measure(*breaks)
+		fmt.Printf("Breaks: %s minutes (%d)\n", formatDecimal(t.Minutes(), 2), sessions)
+	}
+	budgeted := false
+	for _, p := range projects {
+		if b := budgetLabel(p, now); b != "" {
+			if !budgeted {
+				fmt.Println("Budgets:")
+				budgeted = true
+			}
+			fmt.Printf("  %s | %s\n", projectLabel(p, 16), b)
+		}
+	}
+	var expenses float64
+	for _, p := range projects {
+		if x := p.expenseTotal(from, to); x > 0 {
+			if expenses == 0 {
+				fmt.Println("Expenses:")
+			}
+			fmt.Printf("  %s | %s\n", projectLabel(p, 16), formatMoney(x))
+			expenses += x
+		}
+	}
+	if expenses > 0 {
+		fmt.Printf("Total expenses: %s\n", formatMoney(expenses))
+	}
+}
+
+// reportVerify runs verifyReport on the full data and its index.
+func reportVerify(dataPath string, now time.Time) {
+	tracker, err := loadTracker(dataPath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	idx, err := indexFor(dataPath, tracker.Checksum)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	problems := verifyReport(tracker, idx, now)
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) == 0 {
+		fmt.Println("Report math checks out.")
+	} else {
+		fmt.Printf("%d problem(s) found.\n", len(problems))
+	}
+}
+
+func rangeLabel(from, to time.Time) string {
+	f, t := "start", "now"
+	if !from.IsZero() {
+		f = formatDate(from)
+	}
+	if !to.IsZero() {
+		t = formatDate(to.AddDate(0, 0, -1))
+	}
+	return f + " to " + t
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// RoundingConfig is how billed time is rounded, per entry: Increment is
+// a duration such as "6m" or "15m", Mode is "up" (default), "nearest" or
+// "down". Reports apply it by default when Reports is set.
+type RoundingConfig struct {
+	Increment string `json:"increment"`
+	Mode      string `json:"mode,omitempty"`
+	Reports   bool   `json:"reports,omitempty"`
+}
+
+// billingRounder returns a function rounding one entry's duration as
+// configured; without a configured increment it returns d unchanged.
+func billingRounder(c RoundingConfig) (func(time.Duration) time.Duration, error) {
+	if c.Increment == "" {
+		return func(d time.Duration) time.Duration { return d }, nil
+	}
+	inc, err := time.ParseDuration(c.Increment)
+	if err != nil || inc <= 0 {
+		return nil, fmt.Errorf("rounding increment %q: expected a duration such as 15m", c.Increment)
+	}
+	switch c.Mode {
+	case "", "up":
+		return func(d time.Duration) time.Duration {
+			if r := d.Truncate(inc); r < d {
+				return r + inc
+			}
+			return d
+		}, nil
+	case "nearest":
+		return func(d time.Duration) time.Duration { return d.Round(inc) }, nil
+	case "down":
+		return func(d time.Duration) time.Duration { return d.Truncate(inc) }, nil
+	}
+	return nil, fmt.Errorf("rounding mode %q: use up, nearest or down", c.Mode)
+}
+
+// reportRounded compares actual and billed (rounded) time of the closed,
+// billable entries in [from, to) per project.
+func reportRounded(projects []Project, from, to time.Time) {
+	round, err := billingRounder(config.Rounding)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	title := "Summary Report: Billed vs Actual"
+	if !from.IsZero() || !to.IsZero() {
+		title += " (" + rangeLabel(from, to) + ")"
+	}
+	fmt.Println("===================================================================")
+	fmt.Println(title)
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %-11s | %-11s | %-9s\n", "Project", "Actual(min)", "Billed(min)", "Diff(min)")
+	fmt.Println("-----------------|-------------|-------------|----------")
+	var actualAll, billedAll time.Duration
+	var gain float64
+	for _, p := range projects {
+		var actual, billed time.Duration
+		for _, e := range p.Logs {
+			if e.End.IsZero() || !e.isBillable() || !inRange(e.Start, from, to) {
+				continue
+			}
+			d := e.Duration(e.End)
+			actual += d
+			billed += round(d)
+		}
+		if actual == 0 && billed == 0 {
+			continue
+		}
+		money := ""
+		if p.Rate > 0 {
+			diff := earnings(billed-actual, p.Rate)
+			money = " | " + formatMoney(diff)
+			gain += diff
+		}
+		fmt.Printf("%s | %11s | %11s | %9s%s\n", projectLabel(p, 16), formatDecimal(actual.Minutes(), 2), formatDecimal(billed.Minutes(), 2), signedDecimal((billed-actual).Minutes(), 2), money)
+		actualAll += actual
+		billedAll += billed
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("Actual: %s minutes, billed: %s minutes (%s)\n", formatDecimal(actualAll.Minutes(), 2), formatDecimal(billedAll.Minutes(), 2), signedDecimal((billedAll-actualAll).Minutes(), 2))
+	if gain != 0 {
+		fmt.Printf("Rounding changes earnings by %s\n", formatMoney(gain))
+	}
+	if config.Rounding.Increment == "" {
+		fmt.Println(`No "rounding" in config, so billed equals actual.`)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var schemaKinds = []string{"data", "config", "api"}
+
+// jsonSchema builds a JSON Schema (2020-12) document whose root is t,
+// using the same reflection as the OpenAPI document.
+func jsonSchema(title string, t reflect.Type) map[string]any {
+	defs := map[string]any{}
+	root := schemaFor(t, defs, "#/$defs/")
+	doc := map[string]any{
+		"$schema":               "https://json-schema.org/draft/2020-12/schema",
+		"title":                 title,
+		"x-ptracker-version":    version,
+		"x-data-schema-version": dataSchemaVersion,
+		"$defs":                 defs,
+	}
+	for k, v := range root {
+		doc[k] = v
+	}
+	return doc
+}
+
+// apiSchema has a definition for every request and response body of the
+// HTTP API, keyed by type name.
+func apiSchema() map[string]any {
+	defs := map[string]any{}
+	schemaFor(reflect.TypeOf(errorResponse{}), defs, "#/$defs/")
+	for _, rt := range apiRoutes {
+		schemaFor(reflect.TypeOf(rt.Response), defs, "#/$defs/")
+		if rt.Request != nil {
+			schemaFor(reflect.TypeOf(rt.Request), defs, "#/$defs/")
+		}
+	}
+	return map[string]any{
+		"$schema":               "https://json-schema.org/draft/2020-12/schema",
+		"title":                 "ptracker API bodies",
+		"x-ptracker-version":    version,
+		"x-data-schema-version": dataSchemaVersion,
+		"$defs":                 defs,
+	}
+}
+
+func cmdSchema(args []string) {
+	kind := "data"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+	var doc map[string]any
+	switch kind {
+	case "data":
+		doc = jsonSchema(fmt.Sprintf("ptracker data file (schema v%d), also written by 'export json'", dataSchemaVersion), reflect.TypeOf(TrackerData{}))
+	case "config":
+		doc = jsonSchema("ptracker config.json", reflect.TypeOf(Config{}))
+	case "api":
+		doc = apiSchema()
+	default:
+		fmt.Printf("Unknown schema '%s'. Use %s.\n", kind, strings.Join(schemaKinds, ", "))
+		return
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func cmdSearch(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	useRegex := fs.Bool("regex", false, "treat the query as a regular expression")
+	fromStr := fs.String("from", "", "only sessions starting on or after this date")
+	toStr := fs.String("to", "", "only sessions starting on or before this date")
+	project := fs.String("project", "", "only search this project")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Search text required.\n", helpText)
+		return
+	}
+	query := pos[0]
+	from, to, err := parseDateRange(*fromStr, *toStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	match := func(note string) bool {
+		return strings.Contains(strings.ToLower(note), strings.ToLower(query))
+	}
+	if *useRegex {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			fmt.Println("Invalid regex:", err)
+			return
+		}
+		match = re.MatchString
+	}
+
+	count := 0
+	for _, p := range tracker.Projects {
+		if *project != "" && p.Name != *project {
+			continue
+		}
+		for i, e := range p.Logs {
+			if e.Note == "" || !inRange(e.Start, from, to) || !match(e.Note) {
+				continue
+			}
+			dur := e.Duration(now)
+			fmt.Printf("%s | %s #%-3d | %6.2fmin | %s\n", e.Start.Format("2006-01-02 15:04"), projectLabel(p, 12), i+1, dur.Minutes(), e.Note)
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Println("No matching sessions.")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func cmdServe(appDir, dataPath string, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8787", "address to listen on")
+	tlsCert := fs.String("tls-cert", "", "serve HTTPS with this certificate file")
+	tlsKey := fs.String("tls-key", "", "private key for --tls-cert")
+	insecure := fs.Bool("insecure", false, "allow a non-loopback address without API tokens")
+	openapi := fs.Bool("openapi", false, "print the OpenAPI document for the API and exit")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *openapi {
+		data, err := json.MarshalIndent(openAPIDocument(), "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Println("--tls-cert and --tls-key must be given together.")
+		return
+	}
+	valid, err := tokenChecker(appDir)
+	if err != nil {
+		fmt.Println("Error reading tokens:", err)
+		return
+	}
+	if valid == nil && !isLoopback(*listen) && !*insecure {
+		fmt.Println("Refusing to serve on a non-loopback address without API tokens.")
+		fmt.Println("Create one with 'ptracker token create NAME', or pass --insecure.")
+		return
+	}
+	api := &apiServer{store: newStore(dataPath)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		tracker, err := api.store.view()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, tracker, time.Now().UTC())
+	})
+	api.register(mux)
+	api.registerGRPC(mux)
+	api.registerGrafana(mux)
+	go api.serveIPC()
+	if config.Autotrack.Enabled {
+		go api.runAutotrack(config.Autotrack)
+	}
+	go api.runTimeboxes()
+	go api.runBudgets()
+	if config.MQTT.HomeAssistant {
+		go api.runHomeAssistant()
+	}
+	if len(config.Recurring) > 0 {
+		go api.runRecurring(config.Recurring)
+	}
+	if config.Telegram.Token != "" {
+		go api.runTelegram(config.Telegram)
+	}
+	if config.Discord.Webhook != "" {
+		go api.runDiscord(config.Discord)
+	}
+	if config.OnLock == "pause" || config.OnLock == "stop" {
+		go api.runLockWatch(config.OnLock)
+	}
+	var handler http.Handler = mux
+	if valid != nil {
+		handler = requireToken(mux, valid)
+	}
+	handler = uiHandler(handler)
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	fmt.Printf("Serving on %s://%s\n", scheme, *listen)
+	log.Println("serve: listening on", *listen, "auth:", valid != nil, "tls:", *tlsCert != "")
+	// gRPC clients speak HTTP/2 in cleartext too, without an upgrade
+	srv := &http.Server{Addr: *listen, Handler: handler, Protocols: new(http.Protocols)}
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetHTTP2(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	if *tlsCert != "" {
+		err = srv.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil {
+		fmt.Println("Error serving:", err)
+	}
+}
+
+func writeMetrics(w http.ResponseWriter, tracker *TrackerData, now time.Time) {
+	active := 0
+	var b strings.Builder
+	b.WriteString("# HELP ptracker_project_active Whether a session is running for the project.\n")
+	b.WriteString("# TYPE ptracker_project_active gauge\n")
+	for _, p := range tracker.Projects {
+		v := 0
+		if p.running() != nil {
+			v = 1
+			active++
+		}
+		fmt.Fprintf(&b, "ptracker_project_active{project=\"%s\"} %d\n", promLabel(p.Name), v)
+	}
+	b.WriteString("# HELP ptracker_tracked_seconds_total Time tracked on the project, including running sessions.\n")
+	b.WriteString("# TYPE ptracker_tracked_seconds_total counter\n")
+	for _, p := range tracker.Projects {
+		fmt.Fprintf(&b, "ptracker_tracked_seconds_total{project=\"%s\"} %g\n", promLabel(p.Name), projectTotal(p, now).Seconds())
+	}
+	b.WriteString("# HELP ptracker_active_sessions Number of running sessions.\n")
+	b.WriteString("# TYPE ptracker_active_sessions gauge\n")
+	fmt.Fprintf(&b, "ptracker_active_sessions %d\n", active)
+	w.Write([]byte(b.String()))
+}
+
+func promLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
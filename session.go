@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	errAlreadyActive = errors.New("Already active.")
+	errNotActive     = errors.New("Not active.")
+)
+
+type notFoundError string
+
+func (e notFoundError) Error() string {
+	if isCurrentTarget(string(e)) {
+		return fmt.Sprintf("'%s' means the running project, but none or several are running; name one.", string(e))
+	}
+	return fmt.Sprintf("'%s' not found.", string(e))
+}
+
+// running returns p's open entry, or nil when p isn't being tracked. The
+// entry shares p's Logs, so changes to it change p.
+func (p Project) running() *LogEntry {
+	if n := len(p.Logs); n > 0 && p.Logs[n-1].End.IsZero() {
+		return &p.Logs[n-1]
+	}
+	return nil
+}
+
+// startSession opens a new entry on the named project, or on the project
+// of a template given as "@name", which also supplies the note, tags and
+// billable flag. Completed and archived projects need force.
+func startSession(tracker *TrackerData, name, note string, force bool, now time.Time) (*Project, error) {
+	e := LogEntry{Start: now, Note: note, User: currentUser()}
+	if strings.HasPrefix(name, "@") {
+		t, err := lookupTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		name = t.Project
+		if e.Note == "" {
+			e.Note = t.Note
+		}
+		e.Tags = append([]string(nil), t.Tags...)
+		e.Billable = t.Billable
+	}
+	p := findProject(tracker, name)
+	if p == nil {
+		return nil, notFoundError(name)
+	}
+	if p.running() != nil {
+		return p, errAlreadyActive
+	}
+	if p.closed() && !force {
+		return p, closedProjectError{p.Name, p.State}
+	}
+	p.Logs = append(p.Logs, e)
+	return p, nil
+}
+
+// stopSession closes the project's open entry and returns its duration.
+func stopSession(tracker *TrackerData, name, note string, now time.Time) (*Project, time.Duration, error) {
+	p := findProject(tracker, name)
+	if p == nil {
+		return nil, 0, notFoundError(name)
+	}
+	e := p.running()
+	if e == nil {
+		return p, 0, errNotActive
+	}
+	if e.paused() {
+		e.Pauses[len(e.Pauses)-1].End = now
+	}
+	e.End = now
+	dur := e.Duration(now)
+	if note != "" {
+		e.Note = note
+	}
+	p.TotalTime += dur
+	return p, dur, nil
+}
+
+func cmdStart(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("start", flag.ContinueOnError)
+	note := fs.String("note", "", "describe the session")
+	var tags listFlag
+	fs.Var(&tags, "tag", "tag the session (repeatable)")
+	timebox := fs.String("for", "", "stop the session after this long, e.g. 45m (needs serve)")
+	force := fs.Bool("force", false, "start a completed or archived project")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	var d time.Duration
+	if *timebox != "" {
+		if d, err = parseTimebox(*timebox); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	name := pos[0]
+	p, err := startSession(tracker, name, *note, *force, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, t := range tags {
+		p.Logs[len(p.Logs)-1].addTag(t)
+	}
+	if d > 0 {
+		p.Logs[len(p.Logs)-1].Until = now.Add(d)
+	}
+	saveTracker(dataPath, tracker)
+	fmt.Printf("Started '%s' at %s\n", p.Name, now.Format(time.RFC822))
+	if d > 0 {
+		fmt.Printf("Stops at %s.\n", now.Add(d).Format(clockLayout(true)))
+	}
+	if w := budgetWarning(*p, now); w != "" {
+		fmt.Println("Warning:", w)
+	}
+}
+
+func cmdStop(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("stop", flag.ContinueOnError)
+	note := fs.String("note", "", "describe the session")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	targets, err := expandTargets(tracker, pos)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var lines []string
+	stopped := 0
+	for _, p := range targets {
+		_, dur, err := stopSession(tracker, p.Name, *note, now)
+		switch {
+		case err != nil && len(targets) == 1:
+			fmt.Println(err)
+			return
+		case err != nil:
+			lines = append(lines, fmt.Sprintf("'%s': %v", p.Name, err))
+			continue
+		}
+		stopped++
+		lines = append(lines, fmt.Sprintf("Stopped '%s': %.2fmin (Total: %.2fmin)", p.Name, dur.Minutes(), p.TotalTime.Minutes()))
+		if w := budgetWarning(*p, now); w != "" {
+			lines = append(lines, "Warning: "+w)
+		}
+	}
+	if stopped > 0 {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	if len(targets) > 1 {
+		fmt.Printf("Stopped %d of %d projects.\n", stopped, len(targets))
+	}
+}
+
+// stopActive stops every running session, or only the named project's,
+// and returns the stopped projects' names and session lengths.
+func stopActive(tracker *TrackerData, name string, now time.Time) (names []string, durs []time.Duration, err error) {
+	for _, p := range tracker.Projects {
+		if name != "" && !strings.EqualFold(p.Name, name) {
+			continue
+		}
+		if p.running() == nil {
+			continue
+		}
+		_, dur, err := stopSession(tracker, p.Name, "", now)
+		if err != nil {
+			return names, durs, err
+		}
+		names, durs = append(names, p.Name), append(durs, dur)
+	}
+	if len(names) == 0 {
+		if name != "" && findProject(tracker, name) == nil {
+			return nil, nil, notFoundError(name)
+		}
+		return nil, nil, errNotActive
+	}
+	return names, durs, nil
+}
+
+// toggleSession stops the named project (or template's project) if it is
+// active and starts it otherwise, reporting which it did.
+func toggleSession(tracker *TrackerData, name, note string, force bool, now time.Time) (p *Project, started bool, dur time.Duration, err error) {
+	target := name
+	if strings.HasPrefix(name, "@") {
+		t, err := lookupTemplate(name)
+		if err != nil {
+			return nil, false, 0, err
+		}
+		target = t.Project
+	}
+	if p := findProject(tracker, target); p != nil && p.running() != nil {
+		p, dur, err := stopSession(tracker, target, note, now)
+		return p, false, dur, err
+	}
+	p, err = startSession(tracker, name, note, force, now)
+	return p, err == nil, 0, err
+}
+
+// cmdToggle starts the project if it is idle and stops it if it is active.
+// Without a project it toggles the one used most recently.
+func cmdToggle(dataPath string, tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("toggle", flag.ContinueOnError)
+	note := fs.String("note", "", "describe the session")
+	force := fs.Bool("force", false, "start a completed or archived project")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	name := ""
+	if len(pos) > 0 {
+		name = pos[0]
+		if p := lookupProject(tracker, name); p != nil {
+			name = p.Name
+		}
+	} else {
+		var latest time.Time
+		for _, p := range tracker.Projects {
+			if len(p.Logs) > 0 && p.Logs[len(p.Logs)-1].Start.After(latest) {
+				name, latest = p.Name, p.Logs[len(p.Logs)-1].Start
+			}
+		}
+		if name == "" {
+			fmt.Println("No sessions yet; name a project to start.")
+			return
+		}
+	}
+	p, started, dur, err := toggleSession(tracker, name, *note, *force, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	if started {
+		fmt.Printf("Started '%s' at %s\n", p.Name, now.Format(time.RFC822))
+	} else {
+		fmt.Printf("Stopped '%s': %.2fmin (Total: %.2fmin)\n", p.Name, dur.Minutes(), p.TotalTime.Minutes())
+	}
+}
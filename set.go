@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+func cmdSet(dataPath string, tracker *TrackerData, args []string) {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	color := fs.String("color", "", "project color (none to clear)")
+	icon := fs.String("icon", "", "project icon or emoji (none to clear)")
+	desc := fs.String("desc", "", "project description (none to clear)")
+	rate := fs.Float64("rate", 0, "hourly rate (0 to clear)")
+	costRate := fs.Float64("cost-rate", 0, "internal hourly cost (0 to clear)")
+	budget := fs.String("budget", "", "time budget, e.g. 40h (0 to clear)")
+	deadline := fs.String("deadline", "", "due date YYYY-MM-DD (none to clear)")
+	state := fs.String("state", "", "lifecycle state: "+strings.Join(projectStates, ", "))
+	meta := metaFlag{}
+	fs.Var(meta, "meta", "metadata key=value, empty value removes the key (repeatable)")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	if fs.NFlag() == 0 {
+		fmt.Println("Usage: set [project] --color, --icon, --desc, --rate, --cost-rate, --budget, --deadline, --state or --meta key=value")
+		return
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for _, r := range []struct {
+		name  string
+		value float64
+	}{{"rate", *rate}, {"cost-rate", *costRate}} {
+		if given[r.name] && r.value < 0 {
+			fmt.Printf("Invalid --%s %g, it can't be negative.\n", r.name, r.value)
+			return
+		}
+	}
+	p := lookupProject(tracker, pos[0])
+	if p == nil {
+		fmt.Println(notFoundError(pos[0]))
+		return
+	}
+	name := p.Name
+	if *color != "" {
+		if *color == "none" {
+			p.Color = ""
+		} else if _, ok := ansiColors[*color]; ok {
+			p.Color = *color
+		} else {
+			fmt.Printf("Unknown color '%s'.\n", *color)
+			return
+		}
+	}
+	if *icon != "" {
+		if *icon == "none" {
+			p.Icon = ""
+		} else {
+			p.Icon = *icon
+		}
+	}
+	if *desc != "" {
+		if *desc == "none" {
+			p.Description = ""
+		} else {
+			p.Description = *desc
+		}
+	}
+	if given["rate"] {
+		p.Rate = *rate
+	}
+	if given["cost-rate"] {
+		p.CostRate = *costRate
+	}
+	if *budget != "" {
+		d, err := time.ParseDuration(*budget)
+		if err != nil || d < 0 {
+			fmt.Printf("Invalid budget %q, expected e.g. 40h.\n", *budget)
+			return
+		}
+		p.Budget = d
+	}
+	if *state != "" {
+		if !slices.Contains(projectStates, *state) {
+			fmt.Printf("Unknown state '%s'. Use %s.\n", *state, strings.Join(projectStates, ", "))
+			return
+		}
+		p.State = *state
+		if *state == "active" {
+			p.State = ""
+		}
+	}
+	if *deadline == "none" {
+		p.Deadline = time.Time{}
+	} else if *deadline != "" {
+		d, err := parseDate(*deadline, time.Now().UTC())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		p.Deadline = d
+	}
+	for k, v := range meta {
+		if v == "" {
+			delete(p.Meta, k)
+			continue
+		}
+		if p.Meta == nil {
+			p.Meta = map[string]string{}
+		}
+		p.Meta[k] = v
+	}
+	if err := saveTracker(dataPath, tracker); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	fmt.Printf("Updated '%s'.\n", name)
+}
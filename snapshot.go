@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotKeep is how many pre-command snapshots are kept. Snapshots are
+// taken regardless of "backup_keep" and "backup_interval" and pruned
+// separately from the periodic backups.
+const snapshotKeep = 20
+
+// snapshotBefore copies the data file to backups/pre-COMMAND-TIME before
+// a destructive command and returns the copy's path, or "" if there is no
+// data file yet.
+func snapshotBefore(dataPath, command string, now time.Time) (string, error) {
+	if _, err := os.Stat(dataPath); err != nil {
+		return "", nil
+	}
+	if err := os.MkdirAll(backupDir(dataPath), 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Join(backupDir(dataPath), "pre-"+command+"-"+now.Format("20060102-150405")+filepath.Ext(dataPath))
+	if err := copyFile(dataPath, name); err != nil {
+		return "", err
+	}
+	snaps := listSnapshots(dataPath)
+	for _, old := range snaps[min(snapshotKeep, len(snaps)):] {
+		os.Remove(old)
+		os.RemoveAll(archiveSnapshotDir(old))
+	}
+	return name, nil
+}
+
+// listSnapshots returns pre-command snapshots for dataPath, newest first.
+func listSnapshots(dataPath string) []string {
+	entries, _ := os.ReadDir(backupDir(dataPath))
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "pre-") {
+			files = append(files, filepath.Join(backupDir(dataPath), e.Name()))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return snapshotTime(files[i]) > snapshotTime(files[j]) })
+	return files
+}
+
+// snapshotTime is the sortable timestamp at the end of a snapshot name.
+func snapshotTime(name string) string {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if len(base) < 15 {
+		return base
+	}
+	return base[len(base)-15:]
+}
+
+// takeSnapshot snapshots the data file for command, printing any error.
+// It returns false if the command should not go ahead.
+func takeSnapshot(dataPath, command string) (string, bool) {
+	snap, err := snapshotBefore(dataPath, command, time.Now().UTC())
+	if err != nil {
+		fmt.Println("Error taking a snapshot of the data file:", err)
+		return "", false
+	}
+	return snap, true
+}
+
+func printRestoreHint(snap string) {
+	if snap != "" {
+		fmt.Printf("Undo with: ptracker restore %s\n", filepath.Base(snap))
+	}
+}
+
+// cmdRestore replaces the data with a snapshot or backup. Without a file
+// it lists the ones available.
+func cmdRestore(dataPath string, tracker *TrackerData, args []string) {
+	args, yes := cutYes(args)
+	args, force := cutForce(args)
+	if len(args) < 1 {
+		files := append(listSnapshots(dataPath), listBackups(dataPath)...)
+		if len(files) == 0 {
+			fmt.Println("No snapshots or backups.")
+			return
+		}
+		for _, f := range files {
+			fmt.Println(filepath.Base(f))
+		}
+		return
+	}
+	file := args[0]
+	if _, err := os.Stat(file); err != nil {
+		file = filepath.Join(backupDir(dataPath), filepath.Base(args[0]))
+	}
+	if _, err := os.Stat(file); err != nil {
+		fmt.Printf("'%s' not found.\n", args[0])
+		return
+	}
+	in, err := loadTracker(file)
+	if err != nil {
+		fmt.Println("Error reading", args[0]+":", err)
+		return
+	}
+	previewReplace(tracker, in)
+	// a snapshot taken by compact also holds the archives it changed
+	archives := archiveSnapshotDir(file)
+	if fi, err := os.Stat(archives); err != nil || !fi.IsDir() {
+		archives = ""
+	} else {
+		fmt.Println("  archives: back to how they were then")
+	}
+	if !checkReplaceLocked(tracker, in, force) {
+		return
+	}
+	if !confirm(fmt.Sprintf("Replace all %d entries with %d from %s?", countEntries(tracker), countEntries(in), filepath.Base(file)), yes) {
+		return
+	}
+	snap, ok := takeSnapshot(dataPath, "restore")
+	if !ok {
+		return
+	}
+	if archives != "" && snap != "" {
+		if err := snapshotArchives(dataPath, snap); err != nil {
+			fmt.Println("Error taking a snapshot of the archives:", err)
+			return
+		}
+	}
+	// data first: if the archives then fail, entries are counted twice
+	// rather than lost
+	if err := saveTracker(dataPath, in); err != nil {
+		fmt.Println("Error saving data:", err)
+		return
+	}
+	if archives != "" {
+		if err := restoreArchives(dataPath, archives); err != nil {
+			fmt.Println("Error restoring archives:", err)
+			return
+		}
+	}
+	fmt.Printf("Restored %d entries from %s.\n", countEntries(in), filepath.Base(file))
+	printRestoreHint(snap)
+}
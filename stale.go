@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// reportStale lists projects with no activity since now-idle, the most
+// neglected first, as candidates for archiving.
+func reportStale(projects []Project, idle time.Duration, now time.Time) {
+	cutoff := now.Add(-idle)
+	var stale []Project
+	for _, p := range projects {
+		if lastActive(p, now).Before(cutoff) {
+			stale = append(stale, p)
+		}
+	}
+	if len(stale) == 0 {
+		fmt.Printf("No projects idle for %s.\n", formatDays(idle))
+		return
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return lastActive(stale[i], now).Before(lastActive(stale[j], now)) })
+	fmt.Println("===================================================================")
+	fmt.Printf("Stale Projects: no activity for %s\n", formatDays(idle))
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %-11s | %-9s | %-9s\n", "Project", "Last active", "Idle", "Time")
+	fmt.Println("-----------------|-------------|-----------|----------")
+	for _, p := range stale {
+		last, days := "never", "-"
+		if t := lastActive(p, now); !t.IsZero() {
+			last, days = formatDate(t), formatDays(now.Sub(t))
+		}
+		fmt.Printf("%s | %-11s | %-9s | %9s\n", projectLabel(p, 16), last, days, formatHM(projectTotal(p, now)))
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Println("Archive with 'ptracker set PROJECT --state archived'.")
+}
+
+// formatDays renders d in whole days, or hours and minutes if shorter.
+func formatDays(d time.Duration) string {
+	n := int(d.Hours() / 24)
+	if n == 0 {
+		return formatHM(d)
+	}
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// projectStates are the lifecycle states a project can be set to. The
+// empty State is active. Completed and archived projects can't be
+// started without --force, and archived ones are left out of list and
+// report unless asked for.
+var projectStates = []string{"active", "paused", "completed", "archived"}
+
+func (p Project) state() string {
+	if p.State == "" {
+		return "active"
+	}
+	return p.State
+}
+
+func (p Project) closed() bool {
+	return p.State == "completed" || p.State == "archived"
+}
+
+type closedProjectError struct {
+	name, state string
+}
+
+func (e closedProjectError) Error() string {
+	return fmt.Sprintf("'%s' is %s. Use --force to start it anyway, or 'set %s --state active'.", e.name, e.state, e.name)
+}
+
+// cmdArchive archives each project named or matched by a glob in one
+// save, so they drop out of list and report.
+func cmdArchive(dataPath string, tracker *TrackerData, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	targets, err := expandTargets(tracker, args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var lines []string
+	archived := 0
+	for _, p := range targets {
+		if p.State == "archived" {
+			lines = append(lines, fmt.Sprintf("'%s' is already archived.", p.Name))
+			continue
+		}
+		p.State = "archived"
+		archived++
+		line := fmt.Sprintf("Archived '%s'.", p.Name)
+		if p.running() != nil {
+			line += " It is still running; stop it with 'stop " + p.Name + "'."
+		}
+		lines = append(lines, line)
+	}
+	if archived > 0 {
+		if err := saveTracker(dataPath, tracker); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	if len(targets) > 1 {
+		fmt.Printf("Archived %d of %d projects.\n", archived, len(targets))
+	}
+}
+
+// stateFilter checks a --state flag value: a state, or "all". The default
+// "" matches everything but archived projects.
+func stateFilter(s string) (func(Project) bool, error) {
+	switch {
+	case s == "":
+		return func(p Project) bool { return p.State != "archived" }, nil
+	case s == "all":
+		return func(Project) bool { return true }, nil
+	case slices.Contains(projectStates, s):
+		return func(p Project) bool { return p.state() == s }, nil
+	}
+	return nil, fmt.Errorf("unknown state '%s'. Use %s or all", s, strings.Join(projectStates, ", "))
+}
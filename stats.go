@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func cmdStats(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	last := fs.Int("last", 0, "only show the last N sessions")
+	sinceStr := fs.String("since", "", "only show sessions starting on or after this date")
+	pager := fs.Bool("pager", false, "page the output through $PAGER even when not on a terminal")
+	noPager := fs.Bool("no-pager", false, "don't page the output")
+	reverse := fs.Bool("reverse", false, "show the most recent sessions first")
+	all := fs.Bool("all", false, "interleave the sessions of every project")
+	format := fs.String("format", "", "print csv or tsv instead of a table")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return
+	}
+	if err := checkTableFormat(*format); err != nil {
+		fmt.Println(err)
+		return
+	}
+	var since time.Time
+	if *sinceStr != "" {
+		if since, err = parseDate(*sinceStr, now); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if *all {
+		statsAll(tracker, *last, since, autoPager(*pager, *noPager), *reverse, *format, now)
+		return
+	}
+	if len(pos) < 1 {
+		fmt.Println("Project name required.\n", helpText)
+		return
+	}
+	p := lookupProject(tracker, pos[0])
+	if p == nil {
+		fmt.Println(notFoundError(pos[0]))
+		return
+	}
+	name := p.Name
+	first := 0
+	if *last > 0 && *last < len(p.Logs) {
+		first = len(p.Logs) - *last
+	}
+	var shown []int
+	for i := first; i < len(p.Logs); i++ {
+		if since.IsZero() || !p.Logs[i].Start.Before(since) {
+			shown = append(shown, i)
+		}
+	}
+	if *reverse {
+		slices.Reverse(shown)
+	}
+	withPager(autoPager(*pager, *noPager), func(w io.Writer) {
+		if *format != "" {
+			var rows [][]string
+			for _, i := range shown {
+				rows = append(rows, entryRow(p, i, now))
+			}
+			writeTable(w, *format, entryHeader, rows)
+			return
+		}
+		fmt.Fprintln(w, "===============================================")
+		fmt.Fprintf(w, "Stats for %s:\n", name)
+		if p.Description != "" {
+			fmt.Fprintln(w, p.Description)
+		}
+		for _, k := range p.sortedMetaKeys() {
+			fmt.Fprintf(w, "%s: %s\n", k, p.Meta[k])
+		}
+		fmt.Fprintln(w, "===============================================")
+		fmt.Fprintf(w, "Total Sessions: %d | Total Time: %smin\n", len(p.Logs), formatDecimal(p.TotalTime.Minutes(), 2))
+		if len(p.Logs) > 0 {
+			width := len(timestampLayout()) + 1
+			fmt.Fprintf(w, "# | %-*s| %-*s| Duration(min)\n", width, "Start", width, "End")
+			fmt.Fprintf(w, "---|%s|%s|-------------\n", strings.Repeat("-", width+1), strings.Repeat("-", width+1))
+			for _, i := range shown {
+				e := p.Logs[i]
+				start := stampLabel(e.Start, now)
+				end := "-"
+				if !e.End.IsZero() {
+					end = stampLabel(e.End, now)
+				}
+				dur := e.Duration(now)
+				fmt.Fprintf(w, "%-3d| %-*s| %-*s| %6s  %s\n", i+1, width, start, width, end, formatDecimal(dur.Minutes(), 2), e.describe())
+			}
+		}
+	})
+}
+
+// statsAll prints every project's sessions as one chronological log,
+// newest first with reverse. The # column is the entry's number within its project, as used by edit.
+func statsAll(tracker *TrackerData, last int, since time.Time, pager, reverse bool, format string, now time.Time) {
+	type row struct {
+		p *Project
+		i int
+	}
+	var rows []row
+	var total time.Duration
+	for pi := range tracker.Projects {
+		p := &tracker.Projects[pi]
+		for i, e := range p.Logs {
+			if !since.IsZero() && e.Start.Before(since) {
+				continue
+			}
+			rows = append(rows, row{p, i})
+		}
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].p.Logs[rows[a].i].Start.Before(rows[b].p.Logs[rows[b].i].Start)
+	})
+	if last > 0 && last < len(rows) {
+		rows = rows[len(rows)-last:]
+	}
+	if reverse {
+		slices.Reverse(rows)
+	}
+	for _, r := range rows {
+		if r.p.Name != breakProject {
+			total += r.p.Logs[r.i].Duration(now)
+		}
+	}
+	withPager(pager, func(w io.Writer) {
+		if format != "" {
+			var out [][]string
+			for _, r := range rows {
+				out = append(out, entryRow(r.p, r.i, now))
+			}
+			writeTable(w, format, entryHeader, out)
+			return
+		}
+		fmt.Fprintln(w, "===============================================")
+		fmt.Fprintln(w, "Stats for all projects:")
+		fmt.Fprintln(w, "===============================================")
+		fmt.Fprintf(w, "Sessions: %d | Time: %smin\n", len(rows), formatDecimal(total.Minutes(), 2))
+		if len(rows) == 0 {
+			return
+		}
+		width := len(timestampLayout()) + 1
+		fmt.Fprintf(w, "Project          | #   | %-*s| %-*s| Duration(min)\n", width, "Start", width, "End")
+		fmt.Fprintf(w, "-----------------|-----|%s|%s|-------------\n", strings.Repeat("-", width+1), strings.Repeat("-", width+1))
+		for _, r := range rows {
+			e := r.p.Logs[r.i]
+			end := "-"
+			if !e.End.IsZero() {
+				end = stampLabel(e.End, now)
+			}
+			fmt.Fprintf(w, "%s | %-4d| %-*s| %-*s| %6s  %s\n", projectLabel(*r.p, 16), r.i+1, width, stampLabel(e.Start, now), width, end, formatDecimal(e.Duration(now).Minutes(), 2), e.describe())
+		}
+	})
+}
+
+var entryHeader = []string{"project", "entry", "start", "end", "minutes", "note", "tags", "billable"}
+
+// entryRow is p.Logs[i] as a --format row; entry is the 1-based number
+// used by edit and annotate.
+func entryRow(p *Project, i int, now time.Time) []string {
+	e := p.Logs[i]
+	return []string{p.Name, strconv.Itoa(i + 1), timestamp(e.Start), timestamp(e.End), minutes(e.Duration(now)),
+		e.Note, strings.Join(e.Tags, " "), strconv.FormatBool(e.isBillable())}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cmdStatus lists the active sessions. With --project it returns exit
+// code 0 if that project is active and 1 if it is idle (2 if it doesn't
+// exist), so scripts can test it; --quiet prints nothing.
+func cmdStatus(tracker *TrackerData, args []string, now time.Time) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	project := fs.String("project", "", "show only this project and exit non-zero if it is idle")
+	quiet := fs.Bool("quiet", false, "print nothing, only set the exit code")
+	short := fs.Bool("short", false, `print one line, e.g. "acme 1h05m" or "idle"`)
+	if _, err := parseFlags(fs, args); err != nil {
+		return 2
+	}
+	if *project != "" && lookupProject(tracker, *project) == nil {
+		if !*quiet {
+			fmt.Println(notFoundError(*project))
+		}
+		return 2
+	}
+	projects := tracker.Projects
+	if *project != "" {
+		projects = []Project{*lookupProject(tracker, *project)}
+	}
+	if *short {
+		var active []string
+		for _, p := range projects {
+			if e := p.running(); e != nil {
+				active = append(active, p.Name+" "+compactDuration(e.Duration(now)))
+			}
+		}
+		if !*quiet {
+			if len(active) == 0 {
+				fmt.Println("idle")
+			} else {
+				fmt.Println(strings.Join(active, ", "))
+			}
+		}
+		if *project != "" && len(active) == 0 {
+			return 1
+		}
+		return 0
+	}
+	if !*quiet {
+		fmt.Println("Active Sessions:")
+	}
+	count := 0
+	for _, p := range projects {
+		if e := p.running(); e != nil {
+			count++
+			if *quiet {
+				continue
+			}
+			state := ""
+			if p.Rate > 0 && e.isBillable() {
+				state = " | " + formatMoney(earnings(e.Duration(now), p.Rate))
+			}
+			if e.paused() {
+				state += " (paused)"
+			}
+			if !e.Until.IsZero() {
+				if left := e.Until.Sub(now); left > 0 {
+					state += " (" + formatHM(left) + " left)"
+				} else {
+					state += " (timebox over)"
+				}
+			}
+			if b := budgetLabel(p, now); b != "" {
+				state += " | Budget: " + b
+			}
+			started := e.Start.Format(clockLayout(true))
+			if config.RelativeTimes {
+				started = agoLabel(e.Start, now)
+			}
+			fmt.Printf("* %s | Started: %s | Elapsed: %smin%s\n", projectLabel(p, 10), started, formatDecimal(e.Duration(now).Minutes(), 2), state)
+		}
+	}
+	if count == 0 && !*quiet {
+		fmt.Println("None")
+	}
+	if *project != "" && count == 0 {
+		return 1
+	}
+	return 0
+}
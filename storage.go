@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The data file is JSON by default. A data.bin file holds the same
+// TrackerData gob-encoded, which is several times faster to load and save
+// for large histories. The extension picks the encoding.
+const (
+	jsonDataFile   = "data.json"
+	binaryDataFile = "data.bin"
+)
+
+func resolveDataPath(dir string) string {
+	bin := filepath.Join(dir, binaryDataFile)
+	if _, err := os.Stat(bin); err == nil {
+		return bin
+	}
+	return filepath.Join(dir, jsonDataFile)
+}
+
+func isBinary(filename string) bool {
+	return filepath.Ext(filename) == ".bin"
+}
+
+func init() {
+	registerStorage("file", func(dataPath string) Storage { return fileStorage(dataPath) })
+}
+
+// fileStorage is the default driver: the data file itself, encoded as
+// JSON or gob by its extension.
+type fileStorage string
+
+func (f fileStorage) Load() (*TrackerData, error) {
+	filename := string(f)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TrackerData{}, nil
+		}
+		return nil, err
+	}
+	var tracker TrackerData
+	if isBinary(filename) {
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(&tracker)
+	} else {
+		err = json.Unmarshal(data, &tracker)
+	}
+	if err != nil {
+		return nil, &corruptError{filename, err.Error()}
+	}
+	return &tracker, nil
+}
+
+func (f fileStorage) Save(tracker *TrackerData) error {
+	var data []byte
+	var err error
+	if isBinary(string(f)) {
+		var buf bytes.Buffer
+		err = gob.NewEncoder(&buf).Encode(tracker)
+		data = buf.Bytes()
+	} else {
+		data, err = json.MarshalIndent(tracker, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(string(f), data, 0644)
+}
+
+// Watch uses the modification time and size, which every save changes.
+func (f fileStorage) Watch() (string, error) {
+	fi, err := os.Stat(string(f))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", fi.ModTime().UnixNano(), fi.Size()), nil
+}
+
+func (f fileStorage) Lock() (func(), error) {
+	return lockData(string(f))
+}
+
+func loadTracker(filename string) (*TrackerData, error) {
+	s, err := openStorage(filename)
+	if err != nil {
+		return nil, err
+	}
+	tracker, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyTracker(filename, tracker); err != nil {
+		return nil, err
+	}
+	tracker = replayWAL(filename, tracker)
+	if tracker.Version > dataSchemaVersion {
+		return nil, fmt.Errorf("%s uses data schema v%d but this ptracker supports v%d; please upgrade", filename, tracker.Version, dataSchemaVersion)
+	}
+	return tracker, nil
+}
+
+func saveTracker(filename string, tracker *TrackerData) error {
+	if readOnly {
+		return errReadOnly
+	}
+	stampEntries(filename, tracker, time.Now().UTC())
+	if err := rotateBackup(filename, time.Now().UTC()); err != nil {
+		log.Println("backup failed:", err)
+	}
+	var before *TrackerData
+	if wantsSessionEvents(tracker) {
+		before, _ = loadSummary(filename)
+	}
+	if err := writeTracker(filename, tracker); err != nil {
+		return err
+	}
+	if before != nil {
+		announceSessions(before, tracker, time.Now().UTC())
+	}
+	if fi, err := os.Stat(filename); err == nil && !isBinary(filename) {
+		if err := writeSummaryCache(filename, fi, tracker); err != nil {
+			log.Println("summary cache update failed:", err)
+		}
+	}
+	idx := loadIndex(filename)
+	updateIndex(idx, tracker)
+	if err := saveIndex(filename, idx); err != nil {
+		log.Println("index update failed:", err)
+	}
+	return nil
+}
+
+// writeTracker encodes tracker to filename without taking a backup.
+func writeTracker(filename string, tracker *TrackerData) error {
+	for i, p := range tracker.Projects {
+		if p.sessions > len(p.Logs) {
+			return errors.New("refusing to save data loaded as a summary")
+		}
+		// gob can't tell an empty slice from nil; normalize so the
+		// checksum survives a round trip through either encoding
+		if len(p.Logs) == 0 {
+			tracker.Projects[i].Logs = nil
+		}
+	}
+	tracker.Version = dataSchemaVersion
+	base := tracker.Checksum
+	sum, entries, err := checksumProjects(tracker.Projects)
+	if err != nil {
+		return err
+	}
+	tracker.Checksum, tracker.Entries = sum, entries
+	s, err := openStorage(filename)
+	if err != nil {
+		return err
+	}
+	if config.WAL && usesWAL(filename) {
+		if err := writeWAL(filename, base, tracker); err != nil {
+			return err
+		}
+	}
+	if err := s.Save(tracker); err != nil {
+		os.Remove(walPath(filename))
+		return err
+	}
+	os.Remove(walPath(filename))
+	return nil
+}
+
+func cmdConvert(dataPath string, tracker *TrackerData, args []string) {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	format := fs.String("format", "", "target encoding: binary or json")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	var target string
+	switch *format {
+	case "binary":
+		target = filepath.Join(filepath.Dir(dataPath), binaryDataFile)
+	case "json":
+		target = filepath.Join(filepath.Dir(dataPath), jsonDataFile)
+	default:
+		fmt.Println("--format binary or --format json required.")
+		return
+	}
+	if target == dataPath {
+		fmt.Printf("Data is already stored as %s.\n", *format)
+		return
+	}
+	if err := saveTracker(target, tracker); err != nil {
+		fmt.Println("Error writing data:", err)
+		return
+	}
+	if err := os.Rename(dataPath, dataPath+".pre-convert"); err != nil && !os.IsNotExist(err) {
+		fmt.Println("Error moving old data file:", err)
+		return
+	}
+	fmt.Printf("Converted to %s (%s). The old file was kept as %s.pre-convert.\n", *format, filepath.Base(target), filepath.Base(dataPath))
+}
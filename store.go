@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"log"
+	"sync"
+)
+
+// store owns the daemon's view of the data. Mutations are queued and run
+// one at a time by a single goroutine, each against a private copy of the
+// current snapshot that is published only once it has been saved. Readers
+// get the latest published snapshot and never block on writers.
+type store struct {
+	dataPath string
+	txs      chan storeTx
+
+	mu       sync.RWMutex
+	snapshot *TrackerData
+	revision string
+	seq      int
+}
+
+type storeTx struct {
+	desc string
+	fn   func(*TrackerData) error
+	done chan error
+}
+
+func newStore(dataPath string) *store {
+	s := &store{dataPath: dataPath, txs: make(chan storeTx)}
+	go s.run()
+	return s
+}
+
+func (s *store) run() {
+	for tx := range s.txs {
+		tx.done <- s.apply(tx)
+	}
+}
+
+func (s *store) apply(tx storeTx) error {
+	storage, err := openStorage(s.dataPath)
+	if err != nil {
+		return err
+	}
+	unlock, err := storage.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := s.refresh()
+	if err != nil {
+		return err
+	}
+	work, err := cloneTracker(current)
+	if err != nil {
+		return err
+	}
+	if err := tx.fn(work); err != nil {
+		return err
+	}
+	if err := saveTracker(s.dataPath, work); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.seq++
+	s.publish(work)
+	seq := s.seq
+	s.mu.Unlock()
+	log.Printf("tx %d: %s", seq, tx.desc)
+	return nil
+}
+
+// publish records tracker as the snapshot matching the stored data.
+// Callers hold s.mu.
+func (s *store) publish(tracker *TrackerData) {
+	s.snapshot = tracker
+	s.revision = s.watch()
+}
+
+func (s *store) watch() string {
+	storage, err := openStorage(s.dataPath)
+	if err != nil {
+		return ""
+	}
+	rev, _ := storage.Watch()
+	return rev
+}
+
+// refresh returns the current snapshot, reloading it first if the data
+// was changed by another process such as the CLI.
+func (s *store) refresh() (*TrackerData, error) {
+	s.mu.RLock()
+	snap := s.snapshot
+	fresh := snap != nil && s.watch() == s.revision
+	s.mu.RUnlock()
+	if fresh {
+		return snap, nil
+	}
+	tracker, err := loadTracker(s.dataPath)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.publish(tracker)
+	s.mu.Unlock()
+	return tracker, nil
+}
+
+// update queues fn as a transaction and waits for it to commit. fn may
+// return an error to abort without any change becoming visible.
+func (s *store) update(desc string, fn func(*TrackerData) error) error {
+	done := make(chan error, 1)
+	s.txs <- storeTx{desc: desc, fn: fn, done: done}
+	return <-done
+}
+
+// view returns the latest snapshot. It must be treated as read-only.
+func (s *store) view() (*TrackerData, error) {
+	return s.refresh()
+}
+
+func cloneTracker(t *TrackerData) (*TrackerData, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t); err != nil {
+		return nil, err
+	}
+	var out TrackerData
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
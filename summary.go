@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// summaryCommands only look at each project's totals and latest entry, so
+// they load the data file with loadSummary instead of loadTracker.
+var summaryCommands = map[string]bool{
+	"list":    true,
+	"status":  true,
+	"report":  true,
+	"today":   true,
+	"prompt":  true,
+	"current": true,
+	"elapsed": true,
+}
+
+// logSummary decodes a logs array one entry at a time, keeping only the
+// count and the last entry.
+type logSummary struct {
+	count int
+	last  LogEntry
+}
+
+func (l *logSummary) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		var e LogEntry
+		if err := dec.Decode(&e); err != nil {
+			return err
+		}
+		l.count++
+		l.last = e
+	}
+	return nil
+}
+
+type summaryProject struct {
+	Project
+	Logs logSummary `json:"logs"`
+}
+
+// loadSummary streams the data file project by project, keeping only
+// each project's last entry in Logs and the real entry count in sessions.
+// It skips checksum verification, which needs every entry; commands that
+// write go through loadTracker.
+func loadSummary(filename string) (*TrackerData, error) {
+	if isBinary(filename) || (config.Storage != "" && config.Storage != "file") || hasWAL(filename) {
+		return loadTracker(filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TrackerData{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if tracker := readSummaryCache(filename, fi); tracker != nil {
+		return tracker, nil
+	}
+	dec := json.NewDecoder(f)
+	tracker := &TrackerData{}
+	corrupt := func(err error) error { return &corruptError{filename, err.Error()} }
+	if _, err := dec.Token(); err != nil {
+		return nil, corrupt(err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		switch tok {
+		case "projects":
+			if _, err := dec.Token(); err != nil {
+				return nil, corrupt(err)
+			}
+			for dec.More() {
+				var sp summaryProject
+				if err := dec.Decode(&sp); err != nil {
+					return nil, corrupt(err)
+				}
+				p := sp.Project
+				p.sessions = sp.Logs.count
+				if sp.Logs.count > 0 {
+					p.Logs = []LogEntry{sp.Logs.last}
+				}
+				tracker.Projects = append(tracker.Projects, p)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, corrupt(err)
+			}
+		case "version":
+			if err := dec.Decode(&tracker.Version); err != nil {
+				return nil, corrupt(err)
+			}
+		case "checksum":
+			if err := dec.Decode(&tracker.Checksum); err != nil {
+				return nil, corrupt(err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, corrupt(err)
+			}
+		}
+	}
+	if tracker.Version > dataSchemaVersion {
+		return nil, fmt.Errorf("%s uses data schema v%d but this ptracker supports v%d; please upgrade", filename, tracker.Version, dataSchemaVersion)
+	}
+	if !readOnly {
+		if err := writeSummaryCache(filename, fi, tracker); err != nil {
+			log.Println("summary cache update failed:", err)
+		}
+	}
+	return tracker, nil
+}
+
+// summaryCache keeps loadSummary's result for one version of the data
+// file, identified by its size and modification time, so the commands a
+// prompt or status bar polls don't stream a large file on every call.
+type summaryCache struct {
+	Size     int64           `json:"size"`
+	ModTime  time.Time       `json:"modTime"`
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Projects []cachedProject `json:"projects"`
+}
+
+// cachedProject is a project with only its last entry in Logs.
+type cachedProject struct {
+	Project
+	Sessions int `json:"sessions"`
+}
+
+func summaryCachePath(dataPath string) string {
+	return filepath.Join(filepath.Dir(dataPath), "summary.json")
+}
+
+// newSummaryCache summarizes tracker, full or loaded as a summary.
+func newSummaryCache(tracker *TrackerData) summaryCache {
+	c := summaryCache{Version: tracker.Version, Checksum: tracker.Checksum}
+	for _, p := range tracker.Projects {
+		cp := cachedProject{Project: p, Sessions: p.sessionCount()}
+		cp.Logs = nil
+		if len(p.Logs) > 0 {
+			cp.Logs = p.Logs[len(p.Logs)-1:]
+		}
+		c.Projects = append(c.Projects, cp)
+	}
+	return c
+}
+
+// tracker returns the summary as loadSummary would.
+func (c summaryCache) tracker() *TrackerData {
+	tracker := &TrackerData{Version: c.Version, Checksum: c.Checksum}
+	for _, cp := range c.Projects {
+		p := cp.Project
+		p.sessions = cp.Sessions
+		tracker.Projects = append(tracker.Projects, p)
+	}
+	return tracker
+}
+
+// readSummaryCache returns the cached summary if it matches fi.
+func readSummaryCache(dataPath string, fi os.FileInfo) *TrackerData {
+	data, err := os.ReadFile(summaryCachePath(dataPath))
+	if err != nil {
+		return nil
+	}
+	var c summaryCache
+	if json.Unmarshal(data, &c) != nil || c.Size != fi.Size() || !c.ModTime.Equal(fi.ModTime()) {
+		return nil
+	}
+	return c.tracker()
+}
+
+// writeSummaryCache records tracker as the summary of the data file
+// described by fi.
+func writeSummaryCache(dataPath string, fi os.FileInfo, tracker *TrackerData) error {
+	c := newSummaryCache(tracker)
+	c.Size, c.ModTime = fi.Size(), fi.ModTime()
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(summaryCachePath(dataPath), data, 0644)
+}
+
+// sessionCount is the number of entries, which differs from len(p.Logs)
+// for projects loaded by loadSummary.
+func (p Project) sessionCount() int {
+	if p.sessions > 0 {
+		return p.sessions
+	}
+	return len(p.Logs)
+}
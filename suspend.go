@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// lockEvent is a screen lock/unlock or sleep/wake transition. A suspend
+// detected after the fact carries both From and To.
+type lockEvent struct {
+	locked   bool
+	at       time.Time
+	from, to time.Time
+}
+
+// watchDBusSignal runs dbus-monitor for a boolean signal and reports each
+// transition, e.g. logind's PrepareForSleep or ScreenSaver.ActiveChanged.
+func watchDBusSignal(bus, match string, events chan<- lockEvent) {
+	cmd := exec.Command("dbus-monitor", "--"+bus, match)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Println("lockwatch:", err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		log.Println("lockwatch: dbus-monitor unavailable:", err)
+		return
+	}
+	sc := bufio.NewScanner(out)
+	inSignal := false
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "signal "):
+			inSignal = true
+		case inSignal && strings.HasPrefix(line, "boolean "):
+			events <- lockEvent{locked: line == "boolean true", at: time.Now().UTC()}
+			inSignal = false
+		}
+	}
+	cmd.Wait()
+}
+
+// watchClockJumps detects suspends on any platform: Go's monotonic clock
+// stops while the machine sleeps but the wall clock does not.
+func watchClockJumps(events chan<- lockEvent) {
+	const tick = 30 * time.Second
+	last := time.Now()
+	for range time.Tick(tick) {
+		now := time.Now()
+		wall := now.Round(0).Sub(last.Round(0))
+		if wall-now.Sub(last) > time.Minute {
+			events <- lockEvent{from: last.UTC(), to: now.UTC()}
+		}
+		last = now
+	}
+}
+
+// runLockWatch applies config.OnLock ("pause" or "stop") to active
+// sessions when the screen locks or the machine sleeps, resuming paused
+// sessions when it comes back.
+func (s *apiServer) runLockWatch(policy string) {
+	events := make(chan lockEvent)
+	if runtime.GOOS == "linux" && !isTermux() {
+		go watchDBusSignal("system", "type='signal',interface='org.freedesktop.login1.Manager',member='PrepareForSleep'", events)
+		go watchDBusSignal("session", "type='signal',interface='org.freedesktop.ScreenSaver',member='ActiveChanged'", events)
+	}
+	go watchClockJumps(events)
+	log.Println("lockwatch: policy", policy)
+
+	var held []string
+	for ev := range events {
+		err := s.store.update("lockwatch", func(tracker *TrackerData) error {
+			switch {
+			case !ev.from.IsZero():
+				suspended := applySuspend(tracker, policy, ev.from, ev.to)
+				if len(suspended) > 0 {
+					log.Printf("lockwatch: suspend %s-%s applied to %v", ev.from.Format(time.RFC3339), ev.to.Format(time.RFC3339), suspended)
+				}
+			case ev.locked:
+				held = append(held, lockActive(tracker, policy, ev.at)...)
+				log.Printf("lockwatch: locked, %s %v", policy, held)
+			default:
+				if policy == "pause" {
+					resumeProjects(tracker, held, ev.at)
+					log.Printf("lockwatch: unlocked, resumed %v", held)
+				} else if len(held) > 0 {
+					log.Printf("lockwatch: unlocked, %v were stopped at lock", held)
+				}
+				held = nil
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("lockwatch:", err)
+		}
+	}
+}
+
+// lockActive pauses or stops every running, unpaused session at t and
+// returns the affected project names.
+func lockActive(tracker *TrackerData, policy string, t time.Time) []string {
+	var names []string
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		e := p.running()
+		if e == nil || e.paused() {
+			continue
+		}
+		if policy == "stop" {
+			stopSession(tracker, p.Name, "", t)
+		} else {
+			e.Pauses = append(e.Pauses, Pause{Start: t})
+		}
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func resumeProjects(tracker *TrackerData, names []string, t time.Time) {
+	for _, name := range names {
+		p := findProject(tracker, name)
+		if p == nil || len(p.Logs) == 0 || !p.Logs[len(p.Logs)-1].paused() {
+			continue
+		}
+		e := &p.Logs[len(p.Logs)-1]
+		e.Pauses[len(e.Pauses)-1].End = t
+	}
+}
+
+// applySuspend retroactively removes [from, to) from running sessions
+// that weren't already paused or stopped for it by a lock event.
+func applySuspend(tracker *TrackerData, policy string, from, to time.Time) []string {
+	var names []string
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		e := p.running()
+		if e == nil || e.paused() || e.Start.After(from) {
+			continue
+		}
+		if n := len(e.Pauses); n > 0 && e.Pauses[n-1].End.After(from) {
+			continue
+		}
+		if policy == "stop" {
+			stopSession(tracker, p.Name, "", from)
+		} else {
+			e.Pauses = append(e.Pauses, Pause{Start: from, End: to})
+		}
+		names = append(names, p.Name)
+	}
+	return names
+}
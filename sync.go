@@ -0,0 +1,548 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Every entry carries a random ID and the time it was last changed, so two
+// copies of the data can be merged entry by entry. sync keeps the digests
+// of the last merged state per remote and does a three-way merge against
+// it: an entry changed on one side only takes that side, and an entry
+// changed on both is a conflict settled by the chosen policy. Expenses
+// are merged the same way, keeping the later change on a conflict.
+
+func newEntryID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// entryDigest hashes everything about an entry except its Modified stamp.
+func entryDigest(e LogEntry) string {
+	e.Modified = time.Time{}
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:12])
+}
+
+// expenseDigest hashes everything about an expense except its ID and
+// Modified stamp.
+func expenseDigest(x Expense) string {
+	x.ID, x.Modified = "", time.Time{}
+	data, _ := json.Marshal(x)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:12])
+}
+
+// stampExpenses gives p's expenses that have none an ID derived from the
+// project and the expense, so copies saved before expenses had IDs get
+// the same ones on both sides of a sync.
+func stampExpenses(p *Project, now time.Time) {
+	seen := map[string]int{}
+	for i := range p.Expenses {
+		x := &p.Expenses[i]
+		if x.ID != "" {
+			continue
+		}
+		d := expenseDigest(*x)
+		seen[d]++
+		sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%d", p.Name, d, seen[d]))
+		x.ID = hex.EncodeToString(sum[:8])
+		x.Modified = now
+	}
+}
+
+// stampEntries gives new entries and expenses an ID and sets Modified on
+// those that differ from the copy previously saved at filename. Ones
+// whose Modified was already changed, e.g. by sync, keep it.
+func stampEntries(filename string, tracker *TrackerData, now time.Time) {
+	old := map[string]LogEntry{}
+	oldX := map[string]Expense{}
+	if prev, err := loadTracker(filename); err == nil {
+		for _, p := range prev.Projects {
+			for _, e := range p.Logs {
+				if e.ID != "" {
+					old[e.ID] = e
+				}
+			}
+			for _, x := range p.Expenses {
+				if x.ID != "" {
+					oldX[x.ID] = x
+				}
+			}
+		}
+	}
+	for i := range tracker.Projects {
+		stampExpenses(&tracker.Projects[i], now)
+		for j := range tracker.Projects[i].Expenses {
+			x := &tracker.Projects[i].Expenses[j]
+			if o, ok := oldX[x.ID]; ok && x.Modified.Equal(o.Modified) && expenseDigest(*x) != expenseDigest(o) {
+				x.Modified = now
+			}
+		}
+		for j := range tracker.Projects[i].Logs {
+			e := &tracker.Projects[i].Logs[j]
+			if e.ID == "" {
+				e.ID = newEntryID()
+				e.Modified = now
+				continue
+			}
+			if o, ok := old[e.ID]; ok && e.Modified.Equal(o.Modified) && entryDigest(*e) != entryDigest(o) {
+				e.Modified = now
+			}
+		}
+	}
+}
+
+// syncRemote is the other side of a sync: a data file or an HTTP endpoint.
+type syncRemote interface {
+	pull() (*TrackerData, error)
+	push(*TrackerData) error
+}
+
+type fileRemote string
+
+func (f fileRemote) pull() (*TrackerData, error) { return loadTracker(string(f)) }
+
+func (f fileRemote) push(t *TrackerData) error { return writeTracker(string(f), t) }
+
+// httpRemote talks to a 'ptracker server'. The ETag from pull is sent
+// back on push so the server can refuse a push based on stale data.
+type httpRemote struct {
+	url   string
+	token string
+	etag  string
+}
+
+func (h *httpRemote) do(method string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if method == http.MethodPut && h.etag != "" {
+		req.Header.Set("If-Match", h.etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s: %s %s", h.url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (h *httpRemote) pull() (*TrackerData, error) {
+	resp, err := h.do(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return &TrackerData{}, nil
+	}
+	h.etag = resp.Header.Get("ETag")
+	var t TrackerData
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (h *httpRemote) push(t *TrackerData) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	resp, err := h.do(http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// syncBase is the state both sides agreed on after the last sync.
+type syncBase struct {
+	Entries  map[string]string `json:"entries"`
+	Expenses map[string]string `json:"expenses,omitempty"`
+	Projects []string          `json:"projects"`
+}
+
+func syncBasePath(dataPath, remote string) string {
+	sum := sha256.Sum256([]byte(remote))
+	return filepath.Join(filepath.Dir(dataPath), "sync", hex.EncodeToString(sum[:6])+".json")
+}
+
+func loadSyncBase(filename string) (*syncBase, error) {
+	b := &syncBase{Entries: map[string]string{}, Expenses: map[string]string{}}
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, json.Unmarshal(data, b)
+}
+
+func saveSyncBase(filename string, tracker *TrackerData) error {
+	b := syncBase{Entries: map[string]string{}, Expenses: map[string]string{}}
+	for _, p := range tracker.Projects {
+		b.Projects = append(b.Projects, p.Name)
+		for _, e := range p.Logs {
+			b.Entries[e.ID] = entryDigest(e)
+		}
+		for _, x := range p.Expenses {
+			b.Expenses[x.ID] = expenseDigest(x)
+		}
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data, 0644)
+}
+
+type located struct {
+	project string
+	entry   LogEntry
+}
+
+func entriesByID(t *TrackerData) map[string]located {
+	m := map[string]located{}
+	for _, p := range t.Projects {
+		for _, e := range p.Logs {
+			m[e.ID] = located{p.Name, e}
+		}
+	}
+	return m
+}
+
+// conflictPolicy picks between a local and remote version of an entry
+// that changed on both sides since the last sync.
+type conflictPolicy func(local, remote located) located
+
+func newestWins(local, remote located) located {
+	if remote.entry.Modified.After(local.entry.Modified) {
+		return remote
+	}
+	return local
+}
+
+func askConflict(in *bufio.Reader) conflictPolicy {
+	return func(local, remote located) located {
+		show := func(side string, l located) {
+			e := l.entry
+			end := "running"
+			if !e.End.IsZero() {
+				end = e.End.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("  %-6s %s | %s - %s | %s\n", side, l.project, e.Start.Format("2006-01-02 15:04"), end, e.Note)
+		}
+		fmt.Println("Conflict:")
+		show("local", local)
+		show("remote", remote)
+		for {
+			fmt.Print("Keep [l]ocal or [r]emote? ")
+			line, err := readAnswer(in)
+			switch strings.TrimSpace(strings.ToLower(line)) {
+			case "l", "local":
+				return local
+			case "r", "remote":
+				return remote
+			}
+			if err != nil {
+				return local
+			}
+		}
+	}
+}
+
+// mergeTrackers three-way merges local and remote against base. Project
+// settings come from local when both sides have the project.
+func mergeTrackers(local, remote *TrackerData, base *syncBase, resolve conflictPolicy) (*TrackerData, int) {
+	l, r := entriesByID(local), entriesByID(remote)
+	changed := func(x located) bool {
+		d, ok := base.Entries[x.entry.ID]
+		return !ok || d != entryDigest(x.entry)
+	}
+	conflicts := 0
+	var kept []located
+	for id, le := range l {
+		re, ok := r[id]
+		switch {
+		case !ok:
+			// missing remotely: deleted there unless we changed it since
+			if _, known := base.Entries[id]; !known || changed(le) {
+				kept = append(kept, le)
+			}
+		case entryDigest(le.entry) == entryDigest(re.entry):
+			kept = append(kept, le)
+		case changed(le) && changed(re):
+			conflicts++
+			kept = append(kept, resolve(le, re))
+		case changed(re):
+			kept = append(kept, re)
+		default:
+			kept = append(kept, le)
+		}
+	}
+	for id, re := range r {
+		if _, ok := l[id]; ok {
+			continue
+		}
+		if _, known := base.Entries[id]; !known || changed(re) {
+			kept = append(kept, re)
+		}
+	}
+
+	inBase := map[string]bool{}
+	for _, name := range base.Projects {
+		inBase[name] = true
+	}
+	keptX, xConflicts := mergeExpenses(local, remote, base)
+	conflicts += xConflicts
+
+	merged := &TrackerData{}
+	for _, p := range local.Projects {
+		p.Logs, p.Expenses = nil, nil
+		merged.Projects = append(merged.Projects, p)
+	}
+	for _, p := range remote.Projects {
+		if findProject(merged, p.Name) == nil && !inBase[p.Name] {
+			p.Logs, p.Expenses = nil, nil
+			merged.Projects = append(merged.Projects, p)
+		}
+	}
+	place := func(name string) *Project {
+		p := findProject(merged, name)
+		if p == nil {
+			merged.Projects = append(merged.Projects, Project{Name: name})
+			p = &merged.Projects[len(merged.Projects)-1]
+		}
+		return p
+	}
+	for _, k := range kept {
+		insertEntry(place(k.project), k.entry)
+	}
+	for _, k := range keptX {
+		p := place(k.project)
+		p.Expenses = append(p.Expenses, k.expense)
+	}
+	// a project deleted on the remote since the last sync goes away here
+	// too, unless entries or expenses were added to it locally
+	out := merged.Projects[:0]
+	for _, p := range merged.Projects {
+		if inBase[p.Name] && findProject(remote, p.Name) == nil && len(p.Logs) == 0 && len(p.Expenses) == 0 {
+			continue
+		}
+		recomputeTotal(&p)
+		out = append(out, p)
+	}
+	merged.Projects = out
+	// invoices are append-only apart from being marked paid
+	merged.Invoices = append(merged.Invoices, local.Invoices...)
+	for _, inv := range remote.Invoices {
+		if mine := findInvoice(merged, inv.Number); mine == nil {
+			merged.Invoices = append(merged.Invoices, inv)
+		} else if mine.Paid.IsZero() {
+			mine.Paid = inv.Paid
+		}
+	}
+	merged.Locks = append(merged.Locks, local.Locks...)
+	for _, lp := range remote.Locks {
+		if !slices.Contains(merged.Locks, lp) {
+			merged.Locks = append(merged.Locks, lp)
+		}
+	}
+	return merged, conflicts
+}
+
+type placedExpense struct {
+	project string
+	expense Expense
+}
+
+// mergeExpenses three-way merges the expenses of local and remote against
+// base like mergeTrackers does entries, keeping local order followed by
+// expenses new on the remote. An expense changed on both sides keeps the
+// later change.
+func mergeExpenses(local, remote *TrackerData, base *syncBase) ([]placedExpense, int) {
+	byID := func(t *TrackerData) map[string]Expense {
+		m := map[string]Expense{}
+		for i := range t.Projects {
+			stampExpenses(&t.Projects[i], time.Time{})
+			for _, x := range t.Projects[i].Expenses {
+				m[x.ID] = x
+			}
+		}
+		return m
+	}
+	l, r := byID(local), byID(remote)
+	changed := func(x Expense) bool {
+		d, ok := base.Expenses[x.ID]
+		return !ok || d != expenseDigest(x)
+	}
+	conflicts := 0
+	var kept []placedExpense
+	for _, p := range local.Projects {
+		for _, lx := range p.Expenses {
+			rx, ok := r[lx.ID]
+			switch {
+			case !ok:
+				// missing remotely: deleted there unless we changed it since
+				if _, known := base.Expenses[lx.ID]; !known || changed(lx) {
+					kept = append(kept, placedExpense{p.Name, lx})
+				}
+			case expenseDigest(lx) == expenseDigest(rx):
+				kept = append(kept, placedExpense{p.Name, lx})
+			case changed(lx) && changed(rx):
+				conflicts++
+				if rx.Modified.After(lx.Modified) {
+					lx = rx
+				}
+				kept = append(kept, placedExpense{p.Name, lx})
+			case changed(rx):
+				kept = append(kept, placedExpense{p.Name, rx})
+			default:
+				kept = append(kept, placedExpense{p.Name, lx})
+			}
+		}
+	}
+	for _, p := range remote.Projects {
+		for _, rx := range p.Expenses {
+			if _, ok := l[rx.ID]; ok {
+				continue
+			}
+			if _, known := base.Expenses[rx.ID]; !known || changed(rx) {
+				kept = append(kept, placedExpense{p.Name, rx})
+			}
+		}
+	}
+	return kept, conflicts
+}
+
+func cmdSync(dataPath string, tracker *TrackerData, args []string) {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	remoteStr := fs.String("remote", "", "data file path or http(s) URL to sync with")
+	policy := fs.String("policy", "newest", "conflict policy: newest, local, remote or interactive")
+	token := fs.String("token", config.SyncToken, "token for a sync server URL")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *remoteStr == "" {
+		fmt.Println("--remote required.\n", helpText)
+		return
+	}
+	var resolve conflictPolicy
+	switch *policy {
+	case "newest":
+		resolve = newestWins
+	case "local":
+		resolve = func(l, _ located) located { return l }
+	case "remote":
+		resolve = func(_, r located) located { return r }
+	case "interactive":
+		resolve = askConflict(bufio.NewReader(os.Stdin))
+	default:
+		fmt.Printf("Unknown policy '%s'. Use newest, local, remote or interactive.\n", *policy)
+		return
+	}
+
+	var remote syncRemote
+	if strings.HasPrefix(*remoteStr, "http://") || strings.HasPrefix(*remoteStr, "https://") {
+		remote = &httpRemote{url: *remoteStr, token: *token}
+	} else {
+		path, err := filepath.Abs(*remoteStr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if path == dataPath {
+			fmt.Println("Remote is the local data file.")
+			return
+		}
+		release, err := holdLock(fileStorage(path))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer release()
+		remote = fileRemote(path)
+	}
+
+	// make sure every local entry has an ID before comparing
+	stampEntries(dataPath, tracker, time.Now().UTC())
+	theirs, err := remote.pull()
+	if err != nil {
+		fmt.Println("Error reading remote:", err)
+		return
+	}
+	for _, p := range theirs.Projects {
+		for _, e := range p.Logs {
+			if e.ID == "" {
+				fmt.Println("Remote has entries without IDs; run any command that saves against it first, or sync it as the local side.")
+				return
+			}
+		}
+	}
+	basePath := syncBasePath(dataPath, *remoteStr)
+	base, err := loadSyncBase(basePath)
+	if err != nil {
+		fmt.Println("Error reading sync state:", err)
+		return
+	}
+	merged, conflicts := mergeTrackers(tracker, theirs, base, resolve)
+
+	sumMerged, _, _ := checksumProjects(merged.Projects)
+	sumLocal, _, _ := checksumProjects(tracker.Projects)
+	sumRemote, _, _ := checksumProjects(theirs.Projects)
+	if sumMerged != sumLocal {
+		if err := saveTracker(dataPath, merged); err != nil {
+			fmt.Println("Error saving data:", err)
+			return
+		}
+	}
+	if sumMerged != sumRemote {
+		if err := remote.push(merged); err != nil {
+			fmt.Println("Error writing remote:", err)
+			return
+		}
+	}
+	if err := saveSyncBase(basePath, merged); err != nil {
+		fmt.Println("Error saving sync state:", err)
+		return
+	}
+	l, r, m := len(entriesByID(tracker)), len(entriesByID(theirs)), len(entriesByID(merged))
+	fmt.Printf("Synced: %d local, %d remote, %d merged entries", l, r, m)
+	if conflicts > 0 {
+		fmt.Printf(", %d conflicts (%s)", conflicts, *policy)
+	}
+	fmt.Println(".")
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The sync server holds one data file per user under its store directory,
+// served at /sync for 'ptracker sync --remote URL'. Users authenticate
+// with a bearer token; tokens.json keeps only its SHA-256 hash, like the
+// serve API tokens. GET returns the data with its
+// checksum as ETag; PUT must send that ETag in If-Match, so a client that
+// raced another one gets 412 and syncs again instead of overwriting it.
+
+type syncServer struct {
+	dir string
+}
+
+func (s *syncServer) tokensPath() string { return filepath.Join(s.dir, "tokens.json") }
+
+// tokens maps the hash of each token to its user name. Tokens issued
+// before they were hashed are hashed and the file rewritten.
+func (s *syncServer) tokens() (map[string]string, error) {
+	tokens := map[string]string{}
+	data, err := os.ReadFile(s.tokensPath())
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	migrated := false
+	for key, user := range tokens {
+		// plain tokens are 48 hex digits, hashes 64
+		if len(key) != sha256.Size*2 {
+			delete(tokens, key)
+			tokens[hashToken(key)] = user
+			migrated = true
+		}
+	}
+	if migrated {
+		if err := s.saveTokens(tokens); err != nil {
+			return nil, err
+		}
+		log.Println("server: hashed plain tokens in", s.tokensPath())
+	}
+	return tokens, nil
+}
+
+func (s *syncServer) saveTokens(tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.tokensPath(), data, 0600)
+}
+
+func (s *syncServer) addUser(name string) (string, error) {
+	tokens, err := s.tokens()
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 24)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+	tokens[hashToken(token)] = name
+	return token, s.saveTokens(tokens)
+}
+
+func (s *syncServer) userFor(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	tokens, err := s.tokens()
+	if err != nil {
+		log.Println("server: reading tokens:", err)
+		return "", false
+	}
+	h := []byte(hashToken(token))
+	found := ""
+	for hash, user := range tokens {
+		if subtle.ConstantTimeCompare([]byte(hash), h) == 1 {
+			found = user
+		}
+	}
+	return found, found != ""
+}
+
+func (s *syncServer) handleSync(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.userFor(r)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{"invalid or missing token"})
+		return
+	}
+	path := filepath.Join(s.dir, "users", user+".json")
+	unlock, err := lockData(path)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{err.Error()})
+		return
+	}
+	defer unlock()
+	current, err := loadTracker(path)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		return
+	}
+	etag := `"` + current.Checksum + `"`
+	switch r.Method {
+	case http.MethodGet:
+		if current.Checksum == "" {
+			writeJSON(w, http.StatusNotFound, errorResponse{"no data yet"})
+			return
+		}
+		w.Header().Set("ETag", etag)
+		writeJSON(w, http.StatusOK, current)
+	case http.MethodPut:
+		if current.Checksum != "" && r.Header.Get("If-Match") != etag {
+			writeJSON(w, http.StatusPreconditionFailed, errorResponse{"data changed since it was read; sync again"})
+			return
+		}
+		var t TrackerData
+		if err := json.NewDecoder(io.LimitReader(r.Body, 64<<20)).Decode(&t); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+		if err := verifyTracker("request", &t); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+		if err := writeTracker(path, &t); err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+			return
+		}
+		log.Printf("server: %s pushed %d entries", user, t.Entries)
+		w.Header().Set("ETag", `"`+t.Checksum+`"`)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func cmdServer(args []string) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	listen := fs.String("listen", ":9443", "address to listen on")
+	storeDir := fs.String("store", "", "directory holding users' data")
+	addUser := fs.String("add-user", "", "create a token for this user and exit")
+	tlsCert := fs.String("tls-cert", "", "serve HTTPS with this certificate file")
+	tlsKey := fs.String("tls-key", "", "private key for --tls-cert")
+	insecure := fs.Bool("insecure", false, "allow plain HTTP on a non-loopback address")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	if *storeDir == "" {
+		fmt.Println("--store required.\n", helpText)
+		return
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Println("--tls-cert and --tls-key must be given together.")
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(*storeDir, "users"), 0700); err != nil {
+		fmt.Println("Error creating store:", err)
+		return
+	}
+	s := &syncServer{dir: *storeDir}
+	if *addUser != "" {
+		if !validProfileName(*addUser) {
+			fmt.Printf("Invalid user name '%s'.\n", *addUser)
+			return
+		}
+		token, err := s.addUser(*addUser)
+		if err != nil {
+			fmt.Println("Error saving token:", err)
+			return
+		}
+		fmt.Printf("Token for '%s': %s\n", *addUser, token)
+		return
+	}
+	// bearer tokens and everyone's data would cross the network in clear
+	if *tlsCert == "" && !isLoopback(*listen) && !*insecure {
+		fmt.Println("Refusing to serve plain HTTP on a non-loopback address.")
+		fmt.Println("Pass --tls-cert and --tls-key, listen on 127.0.0.1 behind a TLS proxy, or pass --insecure.")
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sync", s.handleSync)
+	mux.HandleFunc("PUT /sync", s.handleSync)
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	fmt.Printf("Sync server on %s://%s, store %s\n", scheme, *listen, *storeDir)
+	log.Println("server: listening on", *listen, "tls:", *tlsCert != "")
+	var err error
+	if *tlsCert != "" {
+		err = http.ListenAndServeTLS(*listen, *tlsCert, *tlsKey, mux)
+	} else {
+		err = http.ListenAndServe(*listen, mux)
+	}
+	if err != nil {
+		fmt.Println("Error serving:", err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// tableFormats are the machine-readable encodings accepted by --format
+// on list, stats and report.
+var tableFormats = []string{"csv", "tsv"}
+
+func checkTableFormat(format string) error {
+	if format == "" || slices.Contains(tableFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("unknown format '%s'; use %s", format, strings.Join(tableFormats, " or "))
+}
+
+// writeTable writes a header row and rows as CSV or TSV. Durations in
+// rows should go through minutes and times through timestamp so every
+// command uses the same units.
+func writeTable(w io.Writer, format string, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	switch {
+	case format == "tsv":
+		cw.Comma = '\t'
+	case currentLocale().decimal == ',':
+		// what spreadsheets expect where the comma is the decimal mark
+		cw.Comma = ';'
+	}
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+func minutes(d time.Duration) string {
+	return formatDecimal(d.Minutes(), 2)
+}
+
+// timestamp formats t as RFC 3339, or empty for the zero time.
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// listFlag collects a repeatable string flag such as --tag.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func (e LogEntry) hasTag(tag string) bool {
+	return slices.Contains(e.Tags, tag)
+}
+
+func (e *LogEntry) addTag(tag string) bool {
+	if e.hasTag(tag) {
+		return false
+	}
+	e.Tags = append(e.Tags, tag)
+	return true
+}
+
+func (e *LogEntry) removeTag(tag string) bool {
+	i := slices.Index(e.Tags, tag)
+	if i < 0 {
+		return false
+	}
+	e.Tags = slices.Delete(e.Tags, i, i+1)
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+	}
+	return true
+}
+
+// isBillable treats entries without an explicit flag as billable.
+func (e LogEntry) isBillable() bool {
+	return e.Billable == nil || *e.Billable
+}
+
+// describe renders the note followed by #tags, for one-line listings.
+func (e LogEntry) describe() string {
+	s := e.Note
+	for _, t := range e.Tags {
+		if s != "" {
+			s += " "
+		}
+		s += "#" + t
+	}
+	if !e.isBillable() {
+		s += " (non-billable)"
+	}
+	if e.AutoClosed {
+		s += " (auto-closed)"
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// expandTargets resolves the project arguments of a batch command: names,
+// "." or "current", and globs such as "experiment-*". Each argument must
+// match at least one project, so a typo fails the whole batch before
+// anything changes. Projects are returned once, in the order given.
+func expandTargets(tracker *TrackerData, args []string) ([]*Project, error) {
+	var targets []*Project
+	seen := map[string]bool{}
+	add := func(p *Project) {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			targets = append(targets, p)
+		}
+	}
+	for _, arg := range args {
+		if p := lookupProject(tracker, arg); p != nil {
+			add(p)
+			continue
+		}
+		if !strings.ContainsAny(arg, "*?[") {
+			return nil, notFoundError(arg)
+		}
+		if _, err := path.Match(arg, ""); err != nil {
+			return nil, fmt.Errorf("Invalid pattern '%s'.", arg)
+		}
+		matched := false
+		for i := range tracker.Projects {
+			if ok, _ := path.Match(arg, tracker.Projects[i].Name); ok {
+				add(&tracker.Projects[i])
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("No project matches '%s'.", arg)
+		}
+	}
+	return targets, nil
+}
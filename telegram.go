@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TelegramConfig connects the Telegram bot run by 'bot telegram' and
+// serve. Only messages from ChatID are obeyed, and alerts go there.
+type TelegramConfig struct {
+	Token  string `json:"token"`
+	ChatID int64  `json:"chat_id,omitempty"`
+}
+
+const telegramAPI = "https://api.telegram.org/bot"
+
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// telegramCall posts params to a Bot API method and decodes its result
+// into out, if given.
+func telegramCall(ctx context.Context, token, method string, params url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramAPI+token+"/"+method,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// the error's URL would contain the token
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !body.OK {
+		return fmt.Errorf("telegram %s: %s", method, body.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(body.Result, out)
+	}
+	return nil
+}
+
+// telegramSend sends text to a chat.
+func telegramSend(token string, chatID int64, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	params := url.Values{"chat_id": {strconv.FormatInt(chatID, 10)}, "text": {text}}
+	return telegramCall(ctx, token, "sendMessage", params, nil)
+}
+
+// telegramAlert sends an alert to the configured chat.
+func telegramAlert(title, body string) error {
+	c := config.Telegram
+	if c.Token == "" || c.ChatID == 0 {
+		return errors.New(`"telegram" needs a token and chat_id in config`)
+	}
+	return telegramSend(c.Token, c.ChatID, title+"\n"+body)
+}
+
+// cmdBot runs a chat bot in the foreground; serve runs it by itself
+// when it is configured.
+func cmdBot(dataPath string, args []string) {
+	if len(args) < 1 || args[0] != "telegram" {
+		fmt.Println("Usage: ptracker bot telegram [--token TOKEN]")
+		return
+	}
+	fs := flag.NewFlagSet("bot telegram", flag.ContinueOnError)
+	token := fs.String("token", config.Telegram.Token, "bot token from @BotFather")
+	if _, err := parseFlags(fs, args[1:]); err != nil {
+		return
+	}
+	if *token == "" {
+		fmt.Println(`Bot token required (--token, or "telegram": {"token": ...} in config).`)
+		return
+	}
+	c := config.Telegram
+	c.Token = *token
+	api := &apiServer{store: newStore(dataPath)}
+	fmt.Println("Telegram bot running; press Ctrl+C to stop.")
+	api.runTelegram(c)
+}
+
+// runTelegram long-polls the Bot API for messages and answers them.
+func (s *apiServer) runTelegram(c TelegramConfig) {
+	var offset int64
+	for {
+		var updates []telegramUpdate
+		ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+		params := url.Values{
+			"offset":          {strconv.FormatInt(offset, 10)},
+			"timeout":         {"30"},
+			"allowed_updates": {`["message"]`},
+		}
+		err := telegramCall(ctx, c.Token, "getUpdates", params, &updates)
+		cancel()
+		if err != nil {
+			log.Println("telegram:", err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || strings.TrimSpace(u.Message.Text) == "" {
+				continue
+			}
+			chat := u.Message.Chat.ID
+			var reply string
+			if chat != c.ChatID {
+				reply = fmt.Sprintf("This chat is not allowed. Add \"chat_id\": %d under \"telegram\" in the ptracker config.", chat)
+			} else {
+				reply = s.telegramCommand(u.Message.Text, time.Now().UTC())
+			}
+			if err := telegramSend(c.Token, chat, reply); err != nil {
+				log.Println("telegram:", err)
+			}
+		}
+	}
+}
+
+// telegramCommand carries out a bot command and returns the reply.
+func (s *apiServer) telegramCommand(text string, now time.Time) string {
+	fields := strings.Fields(text)
+	// in groups commands arrive as /stop@botname
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	arg := strings.Join(fields[1:], " ")
+	switch cmd {
+	case "/start_tracking":
+		if arg == "" {
+			return "Usage: /start_tracking PROJECT"
+		}
+		err := s.store.update("telegram start "+arg, func(tracker *TrackerData) error {
+			_, err := startSession(tracker, arg, "", false, now)
+			return err
+		})
+		if err != nil {
+			return err.Error()
+		}
+		return "Started " + arg + "."
+	case "/stop":
+		var stopped []string
+		err := s.store.update("telegram stop "+arg, func(tracker *TrackerData) error {
+			names, durs, err := stopActive(tracker, arg, now)
+			stopped = nil
+			for i, name := range names {
+				stopped = append(stopped, name+" "+formatHM(durs[i]))
+			}
+			return err
+		})
+		if err != nil {
+			return err.Error()
+		}
+		return "Stopped " + strings.Join(stopped, ", ") + "."
+	case "/today", "/status":
+		tracker, err := s.store.view()
+		if err != nil {
+			return err.Error()
+		}
+		if cmd == "/status" {
+			return telegramStatus(tracker, now)
+		}
+		return telegramToday(tracker, now)
+	}
+	return "Commands: /start_tracking PROJECT, /stop [PROJECT], /today, /status"
+}
+
+func telegramStatus(tracker *TrackerData, now time.Time) string {
+	var lines []string
+	for _, p := range tracker.Projects {
+		if e := p.running(); e != nil {
+			lines = append(lines, p.Name+" "+formatHM(e.Duration(now)))
+		}
+	}
+	if len(lines) == 0 {
+		return "Idle."
+	}
+	return "Tracking " + strings.Join(lines, ", ")
+}
+
+// telegramToday lists the time tracked today per project, longest first.
+func telegramToday(tracker *TrackerData, now time.Time) string {
+	today, _ := parseDate("today", now)
+	type row struct {
+		name string
+		d    time.Duration
+	}
+	var rows []row
+	for _, p := range tracker.Projects {
+		if p.Name == breakProject {
+			continue
+		}
+		days := map[string]time.Duration{}
+		for _, e := range p.Logs {
+			if e.End.IsZero() || e.End.After(today) {
+				addByDay(days, e, now)
+			}
+		}
+		if d := days[dayKey(today)]; d > 0 {
+			rows = append(rows, row{p.Name, d})
+		}
+	}
+	if len(rows) == 0 {
+		return "Nothing tracked today."
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].d > rows[j].d })
+	var b strings.Builder
+	fmt.Fprintf(&b, "Today (%s)\n", formatDate(today))
+	for _, r := range rows {
+		fmt.Fprintf(&b, "%s  %s\n", formatHM(r.d), r.name)
+	}
+	fmt.Fprintf(&b, "%s  total", formatHM(todayTotal(tracker, now)))
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SessionTemplate is a named preset for 'start @name'.
+type SessionTemplate struct {
+	Project  string   `json:"project"`
+	Note     string   `json:"note,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Billable *bool    `json:"billable,omitempty"`
+}
+
+// lookupTemplate resolves "@name" against the templates in config.
+func lookupTemplate(ref string) (SessionTemplate, error) {
+	name := strings.TrimPrefix(ref, "@")
+	t, ok := config.Templates[name]
+	if !ok {
+		var names []string
+		for n := range config.Templates {
+			names = append(names, "@"+n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return t, fmt.Errorf("no template '%s'; define templates under \"templates\" in config", name)
+		}
+		return t, fmt.Errorf("no template '%s' (have %s)", name, strings.Join(names, ", "))
+	}
+	if t.Project == "" {
+		return t, fmt.Errorf("template '%s' has no project", name)
+	}
+	return t, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// isTermux reports whether ptracker runs inside Termux on Android, where
+// there is no desktop, systemd or D-Bus and notifications go through the
+// Termux:API app.
+func isTermux() bool {
+	return runtime.GOOS == "android" || os.Getenv("TERMUX_VERSION") != "" || strings.Contains(os.Getenv("PREFIX"), "com.termux")
+}
+
+// termuxNotify shows an Android notification with termux-notification.
+func termuxNotify(title, body string) error {
+	path, err := exec.LookPath("termux-notification")
+	if err != nil {
+		return fmt.Errorf("termux-notification not found; install the Termux:API app and 'pkg install termux-api'")
+	}
+	return exec.Command(path, "--group", "ptracker", "--title", title, "--content", body).Run()
+}
+
+// cmdShortcuts writes a Termux:Widget script per project to
+// ~/.shortcuts/ptracker, each toggling the project and showing the result
+// as a toast.
+func cmdShortcuts(tracker *TrackerData) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	dir := filepath.Join(home, ".shortcuts", "ptracker")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Println("Error creating directory:", err)
+		return
+	}
+	toast := ""
+	if _, err := exec.LookPath("termux-toast"); err == nil {
+		toast = " 2>&1 | termux-toast"
+	}
+	// Android has no /bin/sh; Termux's shell lives under $PREFIX
+	shell := "/bin/sh"
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		shell = filepath.Join(prefix, "bin", "sh")
+	}
+	n := 0
+	for _, p := range tracker.Projects {
+		if p.closed() || p.Name == breakProject {
+			continue
+		}
+		script := fmt.Sprintf("#!%s\n%s toggle %s%s\n", shell, shellQuote(exe), shellQuote(p.Name), toast)
+		name := strings.ReplaceAll(p.Name, "/", "_")
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			fmt.Println("Error writing shortcut:", err)
+			return
+		}
+		n++
+	}
+	fmt.Printf("Wrote %d shortcuts to %s. Add the Termux:Widget widget to your home screen to use them.\n", n, dir)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
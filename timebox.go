@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var errInvalidDuration = errors.New("invalid duration")
+
+// parseTimebox validates a 'start --for' duration.
+func parseTimebox(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w %q, expected e.g. 45m or 1h30m", errInvalidDuration, s)
+	}
+	return d, nil
+}
+
+// timeboxOver reports whether e is a running timeboxed session whose
+// time is up.
+func timeboxOver(e LogEntry, now time.Time) bool {
+	return e.End.IsZero() && !e.Until.IsZero() && !e.Until.After(now)
+}
+
+// expireTimeboxes stops every running session whose Until has passed, at
+// Until rather than now, and returns a message for each.
+func expireTimeboxes(tracker *TrackerData, now time.Time) []string {
+	var stopped []string
+	for i := range tracker.Projects {
+		p := &tracker.Projects[i]
+		e := p.running()
+		if e == nil || !timeboxOver(*e, now) {
+			continue
+		}
+		at := e.Until
+		if e.paused() && e.Pauses[len(e.Pauses)-1].Start.After(at) {
+			at = e.Pauses[len(e.Pauses)-1].Start
+		}
+		if _, dur, err := stopSession(tracker, p.Name, "", at); err == nil {
+			stopped = append(stopped, fmt.Sprintf("Stopped '%s' after %s.", p.Name, formatHM(dur)))
+		}
+	}
+	return stopped
+}
+
+// runTimeboxes stops timeboxed sessions when they run out and raises a
+// "timebox" alert for each.
+func (s *apiServer) runTimeboxes() {
+	for range time.Tick(15 * time.Second) {
+		tracker, err := s.store.view()
+		if err != nil {
+			log.Println("timebox:", err)
+			continue
+		}
+		due := false
+		for _, p := range tracker.Projects {
+			if e := p.running(); e != nil && timeboxOver(*e, time.Now().UTC()) {
+				due = true
+			}
+		}
+		if !due {
+			continue
+		}
+		var stopped []string
+		err = s.store.update("timebox", func(tracker *TrackerData) error {
+			stopped = expireTimeboxes(tracker, time.Now().UTC())
+			return nil
+		})
+		if err != nil {
+			log.Println("timebox:", err)
+			continue
+		}
+		for _, msg := range stopped {
+			log.Println("timebox:", msg)
+			alert("timebox", "Timebox over", msg)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	timelineSlot  = 5 * time.Minute
+	timelineSlots = int(time.Hour / timelineSlot)
+)
+
+// timelineSymbols gives each project a distinct letter, preferring its
+// initial, for drawing without relying on color.
+func timelineSymbols(projects []Project) map[string]rune {
+	used := map[rune]bool{'~': true}
+	syms := map[string]rune{breakProject: '~'}
+	for _, p := range projects {
+		if _, ok := syms[p.Name]; ok {
+			continue
+		}
+		var pick rune
+		for _, r := range strings.ToUpper(p.Name) + "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789" {
+			if (unicode.IsLetter(r) || unicode.IsDigit(r)) && !used[r] {
+				pick = r
+				break
+			}
+		}
+		if pick == 0 {
+			pick = '#'
+		}
+		used[pick] = true
+		syms[p.Name] = pick
+	}
+	return syms
+}
+
+// cmdTimeline draws the day hour by hour, each hour a bar of 5-minute
+// slots labeled with the project that covered most of the slot, so gaps
+// in the tracked time stand out.
+func cmdTimeline(tracker *TrackerData, args []string, now time.Time) {
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	dateStr := fs.String("date", "today", "day to show")
+	if _, err := parseFlags(fs, args); err != nil {
+		return
+	}
+	day, err := parseDate(*dateStr, now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	dayEnd := day.AddDate(0, 0, 1)
+	slots := make([]map[string]time.Duration, 24*timelineSlots)
+	perProject := map[string]time.Duration{}
+	var first, last time.Time
+	for _, p := range tracker.Projects {
+		for _, e := range p.Logs {
+			for _, iv := range e.intervals(now) {
+				s, t := iv[0], iv[1]
+				if s.Before(day) {
+					s = day
+				}
+				if t.After(dayEnd) {
+					t = dayEnd
+				}
+				if !t.After(s) {
+					continue
+				}
+				if first.IsZero() || s.Before(first) {
+					first = s
+				}
+				if t.After(last) {
+					last = t
+				}
+				perProject[p.Name] += t.Sub(s)
+				for i := int(s.Sub(day) / timelineSlot); i < len(slots); i++ {
+					a := day.Add(time.Duration(i) * timelineSlot)
+					b := a.Add(timelineSlot)
+					if !a.Before(t) {
+						break
+					}
+					if slots[i] == nil {
+						slots[i] = map[string]time.Duration{}
+					}
+					if t.Before(b) {
+						b = t
+					}
+					if s.After(a) {
+						a = s
+					}
+					slots[i][p.Name] += b.Sub(a)
+				}
+			}
+		}
+	}
+	fmt.Printf("Timeline for %s:\n", formatDate(day))
+	if first.IsZero() {
+		fmt.Println("  Nothing tracked.")
+		return
+	}
+	syms := timelineSymbols(tracker.Projects)
+	colors := map[string]string{}
+	for _, p := range tracker.Projects {
+		colors[p.Name] = p.Color
+	}
+	var gaps time.Duration
+	for h := first.Hour(); h <= last.Add(-time.Nanosecond).Hour(); h++ {
+		var b strings.Builder
+		for i := h * timelineSlots; i < (h+1)*timelineSlots; i++ {
+			var name string
+			var most time.Duration
+			for n, d := range slots[i] {
+				if d > most || (d == most && n < name) {
+					name, most = n, d
+				}
+			}
+			a := day.Add(time.Duration(i) * timelineSlot)
+			switch {
+			case name != "":
+				b.WriteString(colorize(colors[name], string(syms[name])))
+			case !a.Before(first) && a.Before(last):
+				b.WriteString("·")
+				gaps += timelineSlot
+			default:
+				b.WriteString(" ")
+			}
+		}
+		fmt.Printf("  %s |%s|\n", day.Add(time.Duration(h)*time.Hour).Format(clockLayout(false)), b.String())
+	}
+	fmt.Println()
+	for _, p := range tracker.Projects {
+		if d := perProject[p.Name]; d > 0 {
+			fmt.Printf("  %s %s %s\n", colorize(p.Color, string(syms[p.Name])), projectLabel(p, 16), formatHM(d))
+		}
+	}
+	fmt.Printf("  · untracked      %s between %s and %s\n", formatHM(gaps), first.Format(clockLayout(false)), last.Format(clockLayout(false)))
+}
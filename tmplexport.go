@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// The data passed to 'export --template'. Field names are part of the
+// template contract: add fields, don't rename them.
+
+type templateEntry struct {
+	Project  string
+	Start    time.Time
+	End      time.Time
+	Running  bool
+	Duration time.Duration
+	Hours    float64
+	Note     string
+	User     string
+}
+
+type templateProject struct {
+	Name        string
+	Description string
+	Meta        map[string]string
+	Rate        float64
+	Entries     []templateEntry
+	Sessions    int
+	Total       time.Duration
+	Hours       float64
+	Earned      float64
+	Expenses    float64
+}
+
+type templateData struct {
+	From, To  time.Time
+	Generated time.Time
+	Projects  []templateProject
+	Entries   []templateEntry
+	Total     time.Duration
+	Hours     float64
+	Earned    float64
+}
+
+var templateFuncs = template.FuncMap{
+	"date":  func(layout string, t time.Time) string { return t.Format(layout) },
+	"money": formatMoney,
+	"hm":    formatHM,
+	"xml":   xmlEscape,
+	"csv": func(fields ...any) string {
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		rec := make([]string, len(fields))
+		for i, f := range fields {
+			rec[i] = fmt.Sprint(f)
+		}
+		w.Write(rec)
+		w.Flush()
+		return strings.TrimSuffix(b.String(), "\n")
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"pad":  func(width int, v any) string { return fmt.Sprintf("%-*s", width, fmt.Sprint(v)) },
+	"lpad": func(width int, v any) string { return fmt.Sprintf("%*s", width, fmt.Sprint(v)) },
+}
+
+func buildTemplateData(tracker *TrackerData, opts exportOptions) templateData {
+	d := templateData{From: opts.from, To: opts.to, Generated: opts.now}
+	for _, p := range opts.selectedProjects(tracker) {
+		tp := templateProject{Name: p.Name, Description: p.Description, Meta: p.Meta, Rate: p.Rate}
+		for _, e := range opts.entries(p) {
+			dur := e.Duration(opts.now)
+			te := templateEntry{
+				Project: p.Name, Start: e.Start, End: e.End, Running: e.End.IsZero(),
+				Duration: dur, Hours: dur.Hours(), Note: e.Note, User: e.User,
+			}
+			tp.Entries = append(tp.Entries, te)
+			tp.Total += dur
+			d.Entries = append(d.Entries, te)
+		}
+		tp.Sessions = len(tp.Entries)
+		tp.Hours = tp.Total.Hours()
+		tp.Earned = earnings(tp.Total, p.Rate)
+		tp.Expenses = p.expenseTotal(opts.from, opts.to)
+		d.Projects = append(d.Projects, tp)
+		d.Total += tp.Total
+		d.Earned += tp.Earned
+	}
+	d.Hours = d.Total.Hours()
+	sort.SliceStable(d.Entries, func(i, j int) bool { return d.Entries[i].Start.Before(d.Entries[j].Start) })
+	return d
+}
+
+// exportTemplate renders the entries through a user-supplied text/template.
+func exportTemplate(w io.Writer, tracker *TrackerData, opts exportOptions, filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(filename).Funcs(templateFuncs).Parse(string(src))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, buildTemplateData(tracker, opts))
+}
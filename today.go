@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func cmdToday(dataPath string, tracker *TrackerData, now time.Time) {
+	idx, err := indexFor(dataPath, tracker.Checksum)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	from, _ := parseDate("today", now)
+	to := from.AddDate(0, 0, 1)
+	fmt.Printf("Today (%s):\n", formatDate(from))
+	var total time.Duration
+	var earned float64
+	billed := false
+	for _, p := range tracker.Projects {
+		t, _ := rangeTotal(idx, p, from, to, now)
+		if t == 0 {
+			continue
+		}
+		active := ""
+		if p.running() != nil {
+			active = " *"
+		}
+		money := ""
+		if p.Rate > 0 {
+			b := billableTotal(p, from, to, now)
+			money = " | " + formatMoney(earnings(b, p.Rate))
+			earned += earnings(b, p.Rate)
+			billed = true
+		}
+		fmt.Printf("  %s | %8smin%s%s\n", projectLabel(p, 16), formatDecimal(t.Minutes(), 2), money, active)
+		if p.Name != breakProject {
+			total += t
+		}
+	}
+	if total == 0 {
+		fmt.Println("  Nothing tracked yet.")
+		return
+	}
+	fmt.Printf("Total: %smin\n", formatDecimal(total.Minutes(), 2))
+	if billed {
+		fmt.Printf("Earned: %s\n", formatMoney(earned))
+	}
+}
+
+// billableTotal is the time of p's billable entries between from and to,
+// split into days like rangeTotal; the index doesn't know what's billable.
+func billableTotal(p Project, from, to, now time.Time) time.Duration {
+	days := map[string]time.Duration{}
+	for _, e := range p.Logs {
+		if e.isBillable() && (e.End.IsZero() || !e.End.Before(from)) {
+			addByDay(days, e, now)
+		}
+	}
+	var total time.Duration
+	for day, d := range days {
+		t, _ := time.Parse(dateLayout, day)
+		if inRange(t, from, to) {
+			total += d
+		}
+	}
+	return total
+}
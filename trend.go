@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values scaled to the largest one.
+func sparkline(values []float64) string {
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = int(v / top * float64(len(sparkBars)-1))
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
+
+// slope is the least-squares change per step of values.
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	var sx, sy, sxy, sxx float64
+	for i, v := range values {
+		x := float64(i)
+		sx += x
+		sy += v
+		sxy += x * v
+		sxx += x * x
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / den
+}
+
+// reportTrend prints hours per week for the weeks covering window up to
+// and including the current one, with a sparkline and the slope.
+func reportTrend(idx *dailyIndex, projects []Project, window time.Duration, now time.Time) {
+	weeks := max(2, int((window+7*24*time.Hour-1)/(7*24*time.Hour)))
+	today, _ := parseDate("today", now)
+	first := weekStart(today).AddDate(0, 0, -7*(weeks-1))
+	fmt.Println("===================================================================")
+	fmt.Printf("Trend: hours per week, %s\n", rangeLabel(first, weekStart(today).AddDate(0, 0, 7)))
+	fmt.Println("===================================================================")
+	header := fmt.Sprintf("%-16s |", "Project")
+	for w := range weeks {
+		_, n := first.AddDate(0, 0, 7*w+3).ISOWeek()
+		header += fmt.Sprintf(" %5s", fmt.Sprintf("W%02d", n))
+	}
+	fmt.Println(header + " | Trend")
+	totals := make([]float64, weeks)
+	for _, p := range projects {
+		values := make([]float64, weeks)
+		any := false
+		for w := range weeks {
+			from := first.AddDate(0, 0, 7*w)
+			t, _ := rangeTotal(idx, p, from, from.AddDate(0, 0, 7), now)
+			values[w] = t.Hours()
+			totals[w] += t.Hours()
+			any = any || t > 0
+		}
+		if any {
+			fmt.Println(trendRow(projectLabel(p, 16), values))
+		}
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Println(trendRow(fmt.Sprintf("%-16s", "Total"), totals))
+}
+
+func trendRow(label string, values []float64) string {
+	row := label + " |"
+	for _, v := range values {
+		row += fmt.Sprintf(" %5s", formatDecimal(v, 1))
+	}
+	return row + fmt.Sprintf(" | %s %sh/wk", sparkline(values), signedDecimal(slope(values), 1))
+}
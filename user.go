@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+)
+
+// currentUser returns the name recorded on new entries: the "user" config
+// setting if present, otherwise the OS username.
+func currentUser() string {
+	if config.User != "" {
+		return config.User
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func reportByUser(projects []Project, from, to, now time.Time) {
+	totals := map[string]time.Duration{}
+	sessions := map[string]int{}
+	var totalAll time.Duration
+	for _, p := range projects {
+		for _, e := range p.Logs {
+			if !inRange(e.Start, from, to) {
+				continue
+			}
+			u := e.User
+			if u == "" {
+				u = "(unknown)"
+			}
+			d := e.Duration(now)
+			totals[u] += d
+			sessions[u]++
+			totalAll += d
+		}
+	}
+	users := make([]string, 0, len(totals))
+	for u := range totals {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+
+	fmt.Println("===================================================================")
+	fmt.Println("Summary Report: By User")
+	fmt.Println("===================================================================")
+	fmt.Printf("%-16s | %-8s | %-10s | %-8s\n", "User", "Sessions", "Time(min)", "Percent")
+	fmt.Println("-----------------|----------|------------|--------")
+	for _, u := range users {
+		fmt.Printf("%-16s | %-8d | %-10s | %6s%%\n", u, sessions[u], formatDecimal(totals[u].Minutes(), 2), formatDecimal(percentShare(totals[u], totalAll), 2))
+	}
+	fmt.Println("-------------------------------------------------------------------")
+	fmt.Printf("Total time tracked: %s minutes\n", formatDecimal(totalAll.Minutes(), 2))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%d)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// dataSchemaVersion is the data file format this build reads and writes.
+// Bump it whenever a change to TrackerData needs a migration.
+const dataSchemaVersion = 1
+
+func cmdVersion() {
+	c, d := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && c == "":
+				c = s.Value
+				if len(c) > 12 {
+					c = c[:12]
+				}
+			case s.Key == "vcs.time" && d == "":
+				d = s.Value
+			}
+		}
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	fmt.Printf("ptracker %s\n", version)
+	fmt.Printf("commit:      %s\n", c)
+	fmt.Printf("built:       %s\n", d)
+	fmt.Printf("data schema: v%d\n", dataSchemaVersion)
+	fmt.Printf("go:          %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
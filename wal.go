@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// With "wal" in config, a JSON data file is saved in two steps: the new
+// data is first written and synced to data.json.wal together with the
+// checksum of the file it replaces, then the data file is rewritten and
+// the log removed. A log left behind by an interrupted save is replayed
+// on the next load, as long as the data file is still the one it was
+// meant to replace; the next save then makes it permanent.
+type walRecord struct {
+	Base string       `json:"base"`
+	Data *TrackerData `json:"data"`
+}
+
+func walPath(dataPath string) string {
+	return dataPath + ".wal"
+}
+
+func usesWAL(dataPath string) bool {
+	return !isBinary(dataPath) && (config.Storage == "" || config.Storage == "file")
+}
+
+func hasWAL(dataPath string) bool {
+	_, err := os.Stat(walPath(dataPath))
+	return err == nil && usesWAL(dataPath)
+}
+
+func writeWAL(dataPath, base string, tracker *TrackerData) error {
+	data, err := json.Marshal(walRecord{base, tracker})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(walPath(dataPath), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replayWAL returns the data of a pending save for tracker, or tracker
+// itself if there is none. A log that is incomplete or was written
+// against a different file is ignored.
+func replayWAL(dataPath string, tracker *TrackerData) *TrackerData {
+	if !usesWAL(dataPath) {
+		return tracker
+	}
+	data, err := os.ReadFile(walPath(dataPath))
+	if err != nil {
+		return tracker
+	}
+	var rec walRecord
+	if err := json.Unmarshal(data, &rec); err != nil || rec.Data == nil {
+		log.Println("wal: ignoring incomplete log", walPath(dataPath))
+		return tracker
+	}
+	if err := verifyTracker(walPath(dataPath), rec.Data); err != nil {
+		log.Println("wal: ignoring log:", err)
+		return tracker
+	}
+	if rec.Data.Checksum == tracker.Checksum {
+		return tracker
+	}
+	if rec.Base != tracker.Checksum {
+		log.Println("wal: ignoring log written against another version of", dataPath)
+		return tracker
+	}
+	log.Println("wal: replaying interrupted save of", dataPath)
+	return rec.Data
+}
@@ -0,0 +1,11 @@
+// Caches the app shell so the home screen app opens offline; API calls
+// always go to the network.
+const shell = "ptracker-v1";
+const files = ["./", "manifest.webmanifest", "icon.svg"];
+
+self.addEventListener("install", e => e.waitUntil(caches.open(shell).then(c => c.addAll(files))));
+
+self.addEventListener("fetch", e => {
+  if (new URL(e.request.url).pathname.startsWith("/v1/")) return;
+  e.respondWith(fetch(e.request).catch(() => caches.match(e.request)));
+});
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"mime"
+	"net/http"
+)
+
+// webFiles is the phone-friendly remote control served by serve at /ui/.
+// It is installable as a PWA and talks to the /v1 API.
+//
+//go:embed web
+var webFiles embed.FS
+
+// uiHandler serves the web UI in front of next. The UI's files hold no
+// data, so they are served without a token; the page asks for one and
+// sends it with its API calls.
+func uiHandler(next http.Handler) http.Handler {
+	files, _ := fs.Sub(webFiles, "web")
+	mime.AddExtensionType(".webmanifest", "application/manifest+json")
+	mux := http.NewServeMux()
+	mux.Handle("GET /ui/", http.StripPrefix("/ui/", http.FileServerFS(files)))
+	mux.Handle("GET /ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+	mux.Handle("/", next)
+	return mux
+}
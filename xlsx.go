@@ -0,0 +1,208 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A minimal SpreadsheetML writer: inline strings, numbers and formulas
+// with cached values, which is all a timesheet needs.
+
+type xlsxCell struct {
+	text    string
+	num     float64
+	formula string
+	isNum   bool
+}
+
+func xlText(s string) xlsxCell               { return xlsxCell{text: s} }
+func xlNum(v float64) xlsxCell               { return xlsxCell{num: v, isNum: true} }
+func xlFormula(f string, v float64) xlsxCell { return xlsxCell{formula: f, num: v, isNum: true} }
+
+type xlsxSheet struct {
+	name string
+	rows [][]xlsxCell
+}
+
+// xlsxColumn turns a 0-based index into a column name: 0 -> A, 26 -> AA.
+func xlsxColumn(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+// xlsxSheetName makes a valid, unique sheet name of at most 31 characters.
+func xlsxSheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	base := name
+	for n := 2; used[strings.ToLower(name)] || name == ""; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		r := []rune(base)
+		if len(r)+len(suffix) > 31 {
+			r = r[:31-len(suffix)]
+		}
+		name = string(r) + suffix
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (s xlsxSheet) xml() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			switch {
+			case cell.formula != "":
+				fmt.Fprintf(&b, `<c r="%s"><f>%s</f><v>%s</v></c>`, ref, xmlEscape(cell.formula), strconv.FormatFloat(cell.num, 'f', -1, 64))
+			case cell.isNum:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(cell.num, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(cell.text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func writeXLSX(filename string, sheets []xlsxSheet) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	z := zip.NewWriter(f)
+	var types, rels, list strings.Builder
+	for i, s := range sheets {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		fmt.Fprintf(&list, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(s.name), i+1, i+1)
+	}
+	const header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + list.String() + `</sheets><calcPr fullCalcOnLoad="1"/></workbook>`},
+		{"xl/_rels/workbook.xml.rels", header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1) +
+			`</Relationships>`},
+		{"xl/styles.xml", header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, body string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+	for _, p := range parts {
+		w, err := z.Create(p.name)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := w.Write([]byte(p.body)); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := z.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportXLSX writes a Summary sheet of per-project totals, computed by
+// formulas over one sheet of sessions per project.
+func exportXLSX(tracker *TrackerData, opts exportOptions) error {
+	if opts.out == "" {
+		return fmt.Errorf("-o FILE required")
+	}
+	used := map[string]bool{"summary": true}
+	summary := xlsxSheet{name: "Summary", rows: [][]xlsxCell{{xlText("Project"), xlText("Sessions"), xlText("Hours"), xlText("Rate"), xlText("Amount")}}}
+	sheets := []xlsxSheet{{}}
+	var total float64
+	for _, p := range opts.selectedProjects(tracker) {
+		entries := opts.entries(p)
+		if len(entries) == 0 {
+			continue
+		}
+		s := xlsxSheet{name: xlsxSheetName(p.Name, used), rows: [][]xlsxCell{{xlText("Date"), xlText("Start"), xlText("End"), xlText("Hours"), xlText("Note")}}}
+		var hours float64
+		for _, e := range entries {
+			end := ""
+			if !e.End.IsZero() {
+				end = e.End.Format("15:04")
+			}
+			h := e.Duration(opts.now).Hours()
+			hours += h
+			s.rows = append(s.rows, []xlsxCell{xlText(e.Start.Format(dateLayout)), xlText(e.Start.Format("15:04")), xlText(end), xlNum(h), xlText(e.Note)})
+		}
+		sheets = append(sheets, s)
+		ref := "'" + strings.ReplaceAll(s.name, "'", "''") + "'!"
+		n := len(s.rows)
+		row := len(summary.rows) + 1
+		summary.rows = append(summary.rows, []xlsxCell{
+			xlText(p.Name),
+			xlFormula(fmt.Sprintf("COUNTA(%sA2:A%d)", ref, n), float64(len(entries))),
+			xlFormula(fmt.Sprintf("SUM(%sD2:D%d)", ref, n), hours),
+			xlNum(p.Rate),
+			xlFormula(fmt.Sprintf("C%d*D%d", row, row), hours*p.Rate),
+		})
+		total += hours * p.Rate
+	}
+	last := len(summary.rows)
+	summary.rows = append(summary.rows, []xlsxCell{
+		xlText("Total"),
+		xlFormula(fmt.Sprintf("SUM(B2:B%d)", last), 0),
+		xlFormula(fmt.Sprintf("SUM(C2:C%d)", last), 0),
+		xlText(""),
+		xlFormula(fmt.Sprintf("SUM(E2:E%d)", last), total),
+	})
+	// cached totals for viewers that don't recalculate
+	for c := 1; c <= 2; c++ {
+		for _, r := range summary.rows[1:last] {
+			summary.rows[last][c].num += r[c].num
+		}
+	}
+	sheets[0] = summary
+	if err := writeXLSX(opts.out, sheets); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (%d project sheets).\n", opts.out, len(sheets)-1)
+	return nil
+}